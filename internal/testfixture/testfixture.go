@@ -0,0 +1,21 @@
+// Package testfixture holds the vite build and root template shared by the tests of the yaigo packages and adapters
+package testfixture
+
+import (
+	"html/template"
+	"testing/fstest"
+)
+
+// Frontend is a vite build with a single entry and its stylesheet
+var Frontend = fstest.MapFS{
+	".vite/manifest.json": &fstest.MapFile{Data: []byte(`{
+	"src/main.ts": {"file": "assets/main-abc123.js", "name": "main", "src": "src/main.ts", "isEntry": true, "css": ["assets/main-def456.css"]}
+}`)},
+	"assets/main-abc123.js":  &fstest.MapFile{Data: []byte("console.log('hi')")},
+	"assets/main-def456.css": &fstest.MapFile{Data: []byte("body{}")},
+}
+
+// Template is a minimal root template rendering the inertia head and root
+func Template(t *template.Template) (*template.Template, error) {
+	return t.Parse(`<html><head>{{ .InertiaHead }}</head><body>{{ .InertiaRoot }}</body></html>`)
+}
@@ -0,0 +1,43 @@
+// Package yaigoecho integrates yaigo with the echo router
+package yaigoecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"net/http"
+)
+
+// Middleware installs the yaigo middleware, the request passed down the chain carries the yaigo context
+func Middleware(config *yaigo.Config, opts ...func(*yaigo.MiddlewareOpts)) echo.MiddlewareFunc {
+	mw := yaigo.Middleware(config, opts...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var err error
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				err = next(c)
+			})).ServeHTTP(c.Response(), c.Request())
+			return err
+		}
+	}
+}
+
+// Render renders the component with the props
+func Render(c echo.Context, component string, props yaigo.Props) error {
+	return RenderPage(c, yaigo.NewPage(component, props))
+}
+
+// RenderPage renders a page created using yaigo.NewPage
+func RenderPage(c echo.Context, page *yaigo.Page) error {
+	return page.Render(c.Request().Context(), c.Response())
+}
+
+// SetProp sets a prop for the page rendered in the current request
+func SetProp(c echo.Context, key string, value any) {
+	yaigo.SetProp(c.Request().Context(), key, value)
+}
+
+// Redirect instructs inertia to redirect properly using http.StatusSeeOther
+func Redirect(c echo.Context, url string) error {
+	return c.Redirect(http.StatusSeeOther, url)
+}
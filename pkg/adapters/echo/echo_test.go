@@ -0,0 +1,93 @@
+package yaigoecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/tortlewortle/yaigo/internal/testfixture"
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestEcho(t *testing.T, opts ...yaigo.OptFunc) *echo.Echo {
+	t.Helper()
+	config, err := yaigo.New(testfixture.Template, testfixture.Frontend, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Use(Middleware(config))
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			SetProp(c, "user", "john")
+			return next(c)
+		}
+	})
+	e.GET("/users/:id", func(c echo.Context) error {
+		return Render(c, "Users/Show", yaigo.Props{"id": c.Param("id")})
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return RenderPage(c, yaigo.NewPage("Errors/NotFound", nil).Status(http.StatusNotFound))
+	})
+	e.POST("/users", func(c echo.Context) error {
+		return Redirect(c, "/users/1")
+	})
+	return e
+}
+
+func TestEcho_Render(t *testing.T) {
+	e := newTestEcho(t)
+
+	r := httptest.NewRequest("GET", "/users/12", nil)
+	r.Header.Set(yaigo.HeaderInertia, "true")
+	r.Header.Set(yaigo.HeaderVersion, "outdated")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, r)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected a version conflict, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/users/12", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{"Users/Show", "&#34;id&#34;:&#34;12&#34;", "&#34;user&#34;:&#34;john&#34;"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %s in: %s", expected, body)
+		}
+	}
+}
+
+func TestEcho_Redirect(t *testing.T) {
+	e := newTestEcho(t)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("POST", "/users", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/users/1" {
+		t.Errorf("unexpected redirect %d to %s", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestEcho_Status(t *testing.T) {
+	for name, opts := range map[string][]yaigo.OptFunc{
+		"default":     nil,
+		"early hints": {yaigo.WithEarlyHints("src/main.ts")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := newTestEcho(t, opts...)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("expected status 404, got %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), "Errors/NotFound") {
+				t.Errorf("expected the page to be rendered: %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/tortlewortle/yaigo/pkg/adapters/echo
+
+go 1.24.0
+
+require (
+	github.com/labstack/echo/v4 v4.13.4
+	github.com/tortlewortle/yaigo v0.0.0-20261019054344-af4e879b0ce5
+)
+
+require (
+	github.com/labstack/gommon v0.5.0 // indirect
+	github.com/mattn/go-colorable v0.1.15 // indirect
+	github.com/mattn/go-isatty v0.0.22 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+)
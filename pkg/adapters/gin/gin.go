@@ -0,0 +1,46 @@
+// Package yaigogin integrates yaigo with the gin router
+package yaigogin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"net/http"
+)
+
+// Middleware installs the yaigo middleware, the request passed down the chain carries the yaigo context
+func Middleware(config *yaigo.Config, opts ...func(*yaigo.MiddlewareOpts)) gin.HandlerFunc {
+	mw := yaigo.Middleware(config, opts...)
+	return func(c *gin.Context) {
+		var called bool
+		mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+
+		// the middleware already responded, e.g. with a version conflict
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// Render renders the component with the props
+func Render(c *gin.Context, component string, props yaigo.Props) error {
+	return RenderPage(c, yaigo.NewPage(component, props))
+}
+
+// RenderPage renders a page created using yaigo.NewPage
+func RenderPage(c *gin.Context, page *yaigo.Page) error {
+	return page.Render(c.Request.Context(), c.Writer)
+}
+
+// SetProp sets a prop for the page rendered in the current request
+func SetProp(c *gin.Context, key string, value any) {
+	yaigo.SetProp(c.Request.Context(), key, value)
+}
+
+// Redirect instructs inertia to redirect properly using http.StatusSeeOther
+func Redirect(c *gin.Context, url string) {
+	c.Redirect(http.StatusSeeOther, url)
+}
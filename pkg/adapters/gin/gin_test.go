@@ -0,0 +1,98 @@
+package yaigogin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tortlewortle/yaigo/internal/testfixture"
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestGin(t *testing.T, opts ...yaigo.OptFunc) *gin.Engine {
+	t.Helper()
+	config, err := yaigo.New(testfixture.Template, testfixture.Frontend, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(Middleware(config))
+	g.Use(func(c *gin.Context) {
+		SetProp(c, "user", "john")
+		c.Next()
+	})
+	g.GET("/users/:id", func(c *gin.Context) {
+		err := Render(c, "Users/Show", yaigo.Props{"id": c.Param("id")})
+		if err != nil {
+			_ = c.Error(err)
+		}
+	})
+	g.GET("/missing", func(c *gin.Context) {
+		err := RenderPage(c, yaigo.NewPage("Errors/NotFound", nil).Status(http.StatusNotFound))
+		if err != nil {
+			_ = c.Error(err)
+		}
+	})
+	g.POST("/users", func(c *gin.Context) {
+		Redirect(c, "/users/1")
+	})
+	return g
+}
+
+func TestGin_Render(t *testing.T) {
+	g := newTestGin(t)
+
+	r := httptest.NewRequest("GET", "/users/12", nil)
+	r.Header.Set(yaigo.HeaderInertia, "true")
+	r.Header.Set(yaigo.HeaderVersion, "outdated")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, r)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected a version conflict, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/users/12", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{"Users/Show", "&#34;id&#34;:&#34;12&#34;", "&#34;user&#34;:&#34;john&#34;"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %s in: %s", expected, body)
+		}
+	}
+}
+
+func TestGin_Redirect(t *testing.T) {
+	g := newTestGin(t)
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("POST", "/users", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/users/1" {
+		t.Errorf("unexpected redirect %d to %s", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestGin_Status(t *testing.T) {
+	for name, opts := range map[string][]yaigo.OptFunc{
+		"default":     nil,
+		"early hints": {yaigo.WithEarlyHints("src/main.ts")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := newTestGin(t, opts...)
+
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("expected status 404, got %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), "Errors/NotFound") {
+				t.Errorf("expected the page to be rendered: %s", rec.Body.String())
+			}
+		})
+	}
+}
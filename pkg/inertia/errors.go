@@ -0,0 +1,54 @@
+package inertia
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusError renders the error page with Status when returned from a Handler
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %v", e.Status, http.StatusText(e.Status), e.Err)
+	}
+	return fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// NewStatusError wraps err so a Handler renders the error page with the status
+func NewStatusError(status int, err error) error {
+	return &StatusError{
+		Status: status,
+		Err:    err,
+	}
+}
+
+var (
+	// ErrNotFound renders the error page with 404, wrap it to add context e.g. fmt.Errorf("user %d: %w", id, ErrNotFound)
+	ErrNotFound = &StatusError{Status: http.StatusNotFound}
+	// ErrForbidden renders the error page with 403
+	ErrForbidden = &StatusError{Status: http.StatusForbidden}
+)
+
+// ValidationError redirects back with the errors flashed when returned from a Handler
+type ValidationError struct {
+	Errors FlashErrors
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for %d fields", len(e.Errors))
+}
+
+// NewValidationError creates a ValidationError from field errors
+func NewValidationError(errs FlashErrors) error {
+	return &ValidationError{
+		Errors: errs,
+	}
+}
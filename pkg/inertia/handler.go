@@ -0,0 +1,85 @@
+package inertia
+
+import (
+	"errors"
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"log/slog"
+	"net/http"
+)
+
+// HandlerFunc returns the page to render, or nil when it wrote the response itself (e.g. a redirect)
+type HandlerFunc = func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error)
+
+// Handler adapts fn to a http.Handler rendering the returned page.
+//
+// Returned errors are mapped to inertia responses:
+//   - *ValidationError redirects back with the errors flashed
+//   - *StatusError (like ErrNotFound and ErrForbidden) renders the error page with the status
+//   - anything else is logged and renders the error page with 500
+//
+// The error page is only rendered when nothing has been written yet, errors after the response started are logged.
+func Handler(fn HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingResponseWriter{ResponseWriter: w}
+		page, err := fn(tw, r)
+		if err == nil {
+			if page == nil {
+				return
+			}
+			err = page.Render(r.Context(), tw)
+			if err == nil {
+				return
+			}
+		}
+
+		if tw.written {
+			yaigo.Logger(r.Context()).Error("handler failed after writing the response", slog.String("url", r.RequestURI), slog.Any("error", err))
+			return
+		}
+
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			Back(w, r, validationErr.Errors)
+			return
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			yaigo.RenderErrorPage(w, r, statusErr.Status, err)
+			return
+		}
+
+		yaigo.Logger(r.Context()).Error("handler failed", slog.String("url", r.RequestURI), slog.Any("error", err))
+		yaigo.RenderErrorPage(w, r, http.StatusInternalServerError, err)
+	})
+}
+
+// trackingResponseWriter records if the response has been started
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingResponseWriter) WriteHeader(code int) {
+	// informational responses like early hints do not start the response
+	if code >= http.StatusOK {
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *trackingResponseWriter) Flush() {
+	w.written = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *trackingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
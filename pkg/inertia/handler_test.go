@@ -0,0 +1,86 @@
+package inertia
+
+import (
+	"errors"
+	"fmt"
+	"github.com/tortlewortle/yaigo/internal/testfixture"
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestHandler(t *testing.T, fn HandlerFunc, opts ...yaigo.OptFunc) http.Handler {
+	t.Helper()
+	config, err := yaigo.New(testfixture.Template, testfixture.Frontend, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return yaigo.Middleware(config)(Handler(fn))
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        HandlerFunc
+		status    int
+		component string
+	}{
+		{"page", func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+			return Page("Welcome", nil), nil
+		}, http.StatusOK, "Welcome"},
+		{"not found", func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+			return nil, fmt.Errorf("user 12: %w", ErrNotFound)
+		}, http.StatusNotFound, "Error"},
+		{"forbidden", func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+			return nil, ErrForbidden
+		}, http.StatusForbidden, "Error"},
+		{"internal", func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+			return nil, errors.New("database down")
+		}, http.StatusInternalServerError, "Error"},
+		{"render error", func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+			return Page("Welcome", Props{"broken": func() {}}), nil
+		}, http.StatusInternalServerError, "Error"},
+		{"validation", func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+			return nil, NewValidationError(FlashErrors{"name": "required"})
+		}, http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/users", nil)
+			r.Header.Set("Referer", "/users/create")
+			rec := httptest.NewRecorder()
+			newTestHandler(t, tt.fn).ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.component != "" && !strings.Contains(rec.Body.String(), "&#34;component&#34;:&#34;"+tt.component+"&#34;") {
+				t.Errorf("expected component %s: %s", tt.component, rec.Body.String())
+			}
+			if tt.status == http.StatusSeeOther && rec.Header().Get("Location") != "/users/create" {
+				t.Errorf("expected a redirect back, got: %s", rec.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestHandler_ErrorAfterWrite(t *testing.T) {
+	// the streamed head is written before encoding the broken prop fails
+	h := newTestHandler(t, func(w http.ResponseWriter, r *http.Request) (*yaigo.Page, error) {
+		return Page("Welcome", Props{"broken": func() {}}), nil
+	}, yaigo.WithHTMLStreaming(true))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the started response to keep its status, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Count(body, "<html>") != 1 || strings.Contains(body, "&#34;component&#34;:&#34;Error&#34;") {
+		t.Errorf("the error page should not be written onto the started response: %s", body)
+	}
+}
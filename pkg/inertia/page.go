@@ -15,3 +15,11 @@ func PageHandler(component string, props Props) http.HandlerFunc {
 		_ = page.Render(r.Context(), w)
 	}
 }
+
+// PageHandlerWithStatus is PageHandler responding with the given status code, e.g. for a 404 page
+func PageHandlerWithStatus(component string, props Props, status int) http.HandlerFunc {
+	page := Page(component, props).Status(status)
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = page.Render(r.Context(), w)
+	}
+}
@@ -2,6 +2,7 @@ package inertia
 
 import (
 	"context"
+	"github.com/tortlewortle/yaigo/pkg/prop"
 	"github.com/tortlewortle/yaigo/pkg/yaigo"
 )
 
@@ -10,3 +11,24 @@ type Props = yaigo.Props
 func SetProp(ctx context.Context, key string, value any) {
 	yaigo.SetProp(ctx, key, value)
 }
+
+// Resolve evaluates the prop concurrently with the other resolved props of the page
+func Resolve(fn func() (any, error)) *prop.LazyProp {
+	return prop.GoAny(func(_ context.Context) (any, error) {
+		return fn()
+	})
+}
+
+// Defer defers the prop to a separate request by inertia, run concurrently with the other deferred props
+func Defer(fn func() (any, error)) *prop.LazyProp {
+	return prop.DeferAny(func(_ context.Context) (any, error) {
+		return fn()
+	})
+}
+
+// DeferSync defers the prop to a separate request by inertia, run sequentially after starting the Defer props
+func DeferSync(fn func() (any, error)) *prop.LazyProp {
+	return prop.DeferPropSync(func(_ context.Context) (any, error) {
+		return fn()
+	})
+}
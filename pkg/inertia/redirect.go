@@ -2,6 +2,7 @@ package inertia
 
 import (
 	"github.com/tortlewortle/yaigo/internal/errflash"
+	"github.com/tortlewortle/yaigo/pkg/route"
 	"github.com/tortlewortle/yaigo/pkg/yaigo"
 	"net/http"
 )
@@ -11,6 +12,19 @@ func Redirect(w http.ResponseWriter, r *http.Request, url string) {
 	http.Redirect(w, r, url, http.StatusSeeOther)
 }
 
+// RedirectRoute redirects to the named route of the route.Router serving the request using http.StatusSeeOther.
+//
+// Building the url can fail for an unknown route or missing params, which is why this is not part of Redirect: it
+// returns the error before anything is written, Redirect itself can not fail.
+func RedirectRoute(w http.ResponseWriter, r *http.Request, name string, params route.Params) error {
+	url, err := route.URL(r.Context(), name, params)
+	if err != nil {
+		return err
+	}
+	Redirect(w, r, url)
+	return nil
+}
+
 // RedirectError instructs inertia to redirect properly using http.StatusSeeOther and sets FlashErrors
 func RedirectError(w http.ResponseWriter, r *http.Request, url string, errs FlashErrors) {
 	if errs != nil {
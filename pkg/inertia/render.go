@@ -0,0 +1,21 @@
+package inertia
+
+import (
+	"github.com/tortlewortle/yaigo/pkg/yaigo"
+	"net/http"
+)
+
+// Render renders the component with the props, using the config provided by the yaigo middleware
+func Render(w http.ResponseWriter, r *http.Request, component string, props Props) error {
+	return Page(component, props).Render(r.Context(), w)
+}
+
+// EncryptHistory overrides the history encryption of the middleware for the current request
+func EncryptHistory(r *http.Request, encrypt bool) {
+	yaigo.EncryptHistory(r.Context(), encrypt)
+}
+
+// ClearHistory clears the client side history for the current request
+func ClearHistory(r *http.Request) {
+	yaigo.ClearHistory(r.Context())
+}
@@ -0,0 +1,183 @@
+package prop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrNoResolver   = errors.New("props can only be awaited from a prop fn")
+	ErrPropCycle    = errors.New("props await each other")
+	ErrResolverDone = errors.New("props can not be evaluated after GetProps returned")
+)
+
+type resolverKey struct{}
+
+// currentPropKey holds the prop that is being evaluated, used to detect await cycles
+type currentPropKey struct{}
+
+// resolver evaluates every prop of a bag at most once per GetProps call, so props can await each other.
+//
+// It does not keep the bag itself, only what it needs of it at the time of the GetProps call. A prop fn that outlives
+// the call, e.g. after a Timeout, can still reach the resolver while the pooled bag already serves another request.
+type resolver struct {
+	// ctx is the context of the GetProps call, dependencies are evaluated with it instead of the awaiting prop's
+	ctx context.Context
+
+	memo        *memoTable
+	sharedCache *SharedCache
+	tracer      Tracer
+	logger      *slog.Logger
+	running     *atomic.Int32
+
+	lock   sync.Mutex
+	byName map[string]*resolvedProp
+	byProp map[*LazyProp]*resolvedProp
+	// done is set once GetProps returned, guarded by lock
+	done bool
+}
+
+type resolvedProp struct {
+	name string
+	lazy *LazyProp
+	once sync.Once
+	val  any
+	err  error
+
+	// waitingOn is the prop this one is currently awaiting, guarded by the resolver lock
+	waitingOn *resolvedProp
+}
+
+// newResolver registers all props of the bag, including the ones filtered out of the response since they can still
+// be awaited, later props take precedence over earlier ones with the same name
+func (b *Bag) newResolver() *resolver {
+	r := &resolver{
+		memo:        b.memo,
+		sharedCache: b.sharedCache,
+		tracer:      b.tracer,
+		logger:      b.logger,
+		running:     &b.running,
+		byName:      make(map[string]*resolvedProp, len(b.valueProps)+len(b.syncProps)+len(b.asyncProps)),
+		byProp:      make(map[*LazyProp]*resolvedProp, len(b.syncProps)+len(b.asyncProps)),
+	}
+	for _, p := range b.valueProps {
+		r.byName[p.name] = &resolvedProp{name: p.name, val: p.value}
+	}
+	for _, props := range [][]*Prop[*LazyProp]{b.asyncProps, b.syncProps} {
+		for _, p := range props {
+			rp := &resolvedProp{name: p.name, lazy: p.value}
+			r.byName[p.name] = rp
+			r.byProp[p.value] = rp
+		}
+	}
+	return r
+}
+
+// lookup returns the entry of a lazy prop, props that were never set in the bag are registered on first use
+func (r *resolver) lookup(p *LazyProp) *resolvedProp {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	rp, ok := r.byProp[p]
+	if !ok {
+		rp = &resolvedProp{name: "<unnamed>", lazy: p}
+		r.byProp[p] = rp
+	}
+	return rp
+}
+
+// finish marks the GetProps call as done, props that were not evaluated by then fail with ErrResolverDone
+func (r *resolver) finish() {
+	r.lock.Lock()
+	r.done = true
+	r.lock.Unlock()
+}
+
+// eval resolves the prop, concurrent callers wait on the first evaluation
+func (r *resolver) eval(rp *resolvedProp) (any, error) {
+	rp.once.Do(func() {
+		if rp.lazy == nil {
+			return
+		}
+		r.lock.Lock()
+		done := r.done
+		r.lock.Unlock()
+		if done {
+			rp.err = ErrResolverDone
+			return
+		}
+
+		ctx := context.WithValue(r.ctx, currentPropKey{}, rp)
+		ctx = context.WithValue(ctx, propCallKey{}, propCall{name: rp.name, logger: r.logger})
+		if r.tracer == nil {
+			rp.val, rp.err = r.resolveLazy(ctx, rp.lazy)
+			return
+		}
+
+		ctx, end := r.tracer.StartProp(ctx, Info{
+			Name:     rp.name,
+			Group:    rp.lazy.group,
+			Sync:     rp.lazy.sync,
+			Deferred: rp.lazy.deferred,
+		})
+		rp.val, rp.err = r.resolveLazy(ctx, rp.lazy)
+		end(rp.err)
+	})
+	return rp.val, rp.err
+}
+
+// await evaluates dep on behalf of the prop being evaluated in ctx, failing instead of deadlocking when the
+// props end up waiting on each other
+func (r *resolver) await(ctx context.Context, dep *resolvedProp) (any, error) {
+	current, ok := ctx.Value(currentPropKey{}).(*resolvedProp)
+	if !ok {
+		return r.eval(dep)
+	}
+
+	r.lock.Lock()
+	for rp := dep; rp != nil; rp = rp.waitingOn {
+		if rp == current {
+			r.lock.Unlock()
+			return nil, fmt.Errorf("%w: %q awaits %q", ErrPropCycle, current.name, dep.name)
+		}
+	}
+	current.waitingOn = dep
+	r.lock.Unlock()
+
+	val, err := r.eval(dep)
+
+	r.lock.Lock()
+	current.waitingOn = nil
+	r.lock.Unlock()
+	return val, err
+}
+
+// Await returns the resolved value of another prop in the same bag, evaluating it if it has not been started yet.
+//
+// Each prop is only evaluated once per render so dependent props can share an expensive fetch, e.g.
+//
+//	bag.Set("user", prop.GoAny(fetchUser))
+//	bag.Set("team", prop.GoAny(func(ctx context.Context) (any, error) {
+//		user, err := prop.Await(ctx, "user")
+//		...
+//	}))
+//
+// Props awaiting each other fail with ErrPropCycle. Use Lazy for a typed handle.
+func Await(ctx context.Context, name string) (any, error) {
+	r, ok := ctx.Value(resolverKey{}).(*resolver)
+	if !ok {
+		return nil, ErrNoResolver
+	}
+	rp, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("await prop %q: unknown prop", name)
+	}
+	val, err := r.await(ctx, rp)
+	if err != nil {
+		return nil, fmt.Errorf("await prop %q: %w", name, err)
+	}
+	return val, nil
+}
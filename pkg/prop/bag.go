@@ -3,8 +3,10 @@ package prop
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"slices"
 	"sync"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -24,6 +26,18 @@ type Bag struct {
 
 	dirty        bool
 	loadDeferred bool
+
+	limit int
+
+	// memo is kept for the whole request, unlike props it survives a rollback
+	memo        *memoTable
+	sharedCache *SharedCache
+
+	tracer Tracer
+	logger *slog.Logger
+
+	// running counts the prop fns running in their own goroutine, see Abandoned
+	running atomic.Int32
 }
 
 type Prop[T any] struct {
@@ -39,6 +53,7 @@ func NewBag() *Bag {
 		// re-usable ish
 		deferredProps: make(map[string][]string),
 		props:         make(map[string]any),
+		memo:          newMemoTable(),
 	}
 }
 
@@ -108,10 +123,25 @@ func (b *Bag) LoadDeferred() *Bag {
 	return b
 }
 
+// SetSharedCache sets the cache used to deduplicate Shared Memo props across requests
+func (b *Bag) SetSharedCache(c *SharedCache) *Bag {
+	b.sharedCache = c
+	return b
+}
+
+// SetLimit limits how many async props are resolved at the same time, n <= 0 means no limit
+func (b *Bag) SetLimit(n int) *Bag {
+	b.limit = n
+	return b
+}
+
 // GetProps calculates, evaluates, and returns the props for the current render cycle
 //
 // Deferred props will only be loaded when explicitly asked for.
 func (b *Bag) GetProps(ctx context.Context) (map[string]any, error) {
+	// the resolver needs every prop, filtered props can still be awaited by the others
+	res := b.newResolver()
+	defer res.finish()
 	b.filterProps()
 	var lock sync.Mutex
 
@@ -119,6 +149,11 @@ func (b *Bag) GetProps(ctx context.Context) (map[string]any, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	g, ctx := errgroup.WithContext(ctx)
+	if b.limit > 0 {
+		g.SetLimit(b.limit)
+	}
+	ctx = context.WithValue(ctx, resolverKey{}, res)
+	res.ctx = ctx
 
 	// copy value props over
 	for _, prop := range b.valueProps {
@@ -129,7 +164,7 @@ func (b *Bag) GetProps(ctx context.Context) (map[string]any, error) {
 
 	for _, p := range b.asyncProps {
 		g.Go(func() error {
-			val, err := p.value.fn(ctx)
+			val, err := res.eval(res.byName[p.name])
 			if err != nil {
 				return fmt.Errorf("eval async prop %q: %w", p.name, err)
 			}
@@ -149,7 +184,7 @@ func (b *Bag) GetProps(ctx context.Context) (map[string]any, error) {
 
 	lock.Lock()
 	for _, p := range b.syncProps {
-		val, err := p.value.fn(ctx)
+		val, err := res.eval(res.byName[p.name])
 		if err != nil {
 			// unlock so we don't potentially deadlock asyncProp goroutines
 			lock.Unlock()
@@ -170,12 +205,29 @@ func (b *Bag) GetProps(ctx context.Context) (map[string]any, error) {
 	return b.props, nil
 }
 
+// Abandoned reports if a prop fn that exceeded its Timeout is still running in the background.
+//
+// The fn can still reach everything in the context passed to GetProps, so the bag and other request state should not
+// be reused for another request then.
+func (b *Bag) Abandoned() bool {
+	return b.running.Load() > 0
+}
+
 // GetDeferredProps returns the props deferred after a GetProps call
 func (b *Bag) GetDeferredProps() map[string][]string {
 	return b.deferredProps
 }
 
+// lazyHandle is implemented by the typed Lazy handles
+type lazyHandle interface {
+	lazyProp() *LazyProp
+}
+
 func (b *Bag) Set(key string, value any) {
+	if h, ok := value.(lazyHandle); ok {
+		value = h.lazyProp()
+	}
+
 	switch p := value.(type) {
 	case *LazyProp:
 		prop := &Prop[*LazyProp]{
@@ -220,6 +272,13 @@ func (b *Bag) Empty() {
 	b.dirty = false
 	b.onlyProps = nil
 	b.exceptProps = nil
+	b.limit = 0
+
+	// replaced instead of cleared, a prop fn of this request may still be running
+	b.memo = newMemoTable()
+	b.sharedCache = nil
+	b.tracer = nil
+	b.logger = nil
 }
 
 // filterProps throws out any props that are not meant to be loaded
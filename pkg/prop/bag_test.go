@@ -1,9 +1,15 @@
 package prop
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"log/slog"
 	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestBag_Except(t *testing.T) {
@@ -393,3 +399,584 @@ func TestBag_SetDeferred(t *testing.T) {
 		t.Error("invalid prop name in age group")
 	}
 }
+
+func TestBag_Timeout(t *testing.T) {
+	b := NewBag()
+
+	b.Set("slow", GoAny(func(ctx context.Context) (any, error) {
+		time.Sleep(time.Second)
+		return "slow", nil
+	}).Timeout(10*time.Millisecond).Fallback("fallback"))
+
+	b.Set("slowNoFallback", NewLazyProp(func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, false, true).Timeout(10*time.Millisecond))
+
+	b.Set("fast", GoAny(func(ctx context.Context) (any, error) {
+		return "fast", nil
+	}).Timeout(time.Second))
+
+	start := time.Now()
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("slow prop should not block the page past its timeout")
+	}
+
+	if props["slow"] != "fallback" {
+		t.Errorf("expected fallback, got: %v", props["slow"])
+	}
+	if res, ok := props["slowNoFallback"].(Result[any]); !ok || res.Error == nil {
+		t.Errorf("expected a Result error, got: %v", props["slowNoFallback"])
+	}
+	if props["fast"] != "fast" {
+		t.Errorf("expected fast, got: %v", props["fast"])
+	}
+}
+
+func TestBag_Abandoned(t *testing.T) {
+	release := make(chan struct{})
+	finished := make(chan struct{})
+
+	b := NewBag()
+	b.Set("slow", GoAny(func(ctx context.Context) (any, error) {
+		defer close(finished)
+		<-release
+		return "slow", nil
+	}).Timeout(10*time.Millisecond))
+	b.Set("fast", GoAny(func(ctx context.Context) (any, error) {
+		return "fast", nil
+	}).Timeout(time.Second))
+
+	_, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Abandoned() {
+		t.Error("expected the timed out fn to be reported while it is still running")
+	}
+
+	close(release)
+	<-finished
+	// the counter is decremented right after the fn returns
+	deadline := time.Now().Add(time.Second)
+	for b.Abandoned() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if b.Abandoned() {
+		t.Error("expected the bag not to be abandoned once the fn returned")
+	}
+}
+
+func TestBag_Fallback(t *testing.T) {
+	b := NewBag()
+
+	b.Set("broken", GoAny(func(ctx context.Context) (any, error) {
+		return nil, errors.New("broken")
+	}).Fallback([]string{}))
+
+	b.Set("brokenSync", DeferPropSync(func(ctx context.Context) (any, error) {
+		return nil, errors.New("broken")
+	}).Fallback(nil))
+	b.LoadDeferred()
+
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := props["broken"].([]string); !ok || len(v) != 0 {
+		t.Errorf("expected fallback, got: %v", props["broken"])
+	}
+	if v, ok := props["brokenSync"]; !ok || v != nil {
+		t.Errorf("expected nil fallback, got: %v", v)
+	}
+
+	b = NewBag()
+	b.Set("broken", GoAny(func(ctx context.Context) (any, error) {
+		return nil, errors.New("broken")
+	}))
+	_, err = b.GetProps(context.Background())
+	if err == nil {
+		t.Error("props without a fallback should still fail the page")
+	}
+}
+
+func TestBag_SetLimit(t *testing.T) {
+	b := NewBag().SetLimit(2)
+
+	var running, peak atomic.Int32
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		b.Set(name, GoAny(func(ctx context.Context) (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return name, nil
+		}))
+	}
+
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(props) != 5 {
+		t.Errorf("expected 5 props, got: %v", props)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent props, got: %d", peak.Load())
+	}
+}
+
+func TestBag_Await(t *testing.T) {
+	b := NewBag().SetLimit(1)
+
+	var calls atomic.Int32
+	b.Set("user", GoAny(func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return "john", nil
+	}))
+	b.Set("greeting", GoAny(func(ctx context.Context) (any, error) {
+		user, err := Await(ctx, "user")
+		if err != nil {
+			return nil, err
+		}
+		return "hello " + user.(string), nil
+	}))
+	b.Set("syncGreeting", NewLazyProp(func(ctx context.Context) (any, error) {
+		user, err := Await(ctx, "user")
+		if err != nil {
+			return nil, err
+		}
+		return "hi " + user.(string), nil
+	}, false, true))
+	b.Set("role", "admin")
+	b.Set("title", GoAny(func(ctx context.Context) (any, error) {
+		role, err := Await(ctx, "role")
+		if err != nil {
+			return nil, err
+		}
+		return role.(string) + " panel", nil
+	}))
+	b.Only([]string{"greeting", "syncGreeting", "title"})
+
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["greeting"] != "hello john" {
+		t.Errorf("expected hello john, got: %v", props["greeting"])
+	}
+	if props["syncGreeting"] != "hi john" {
+		t.Errorf("expected hi john, got: %v", props["syncGreeting"])
+	}
+	if props["title"] != "admin panel" {
+		t.Errorf("expected admin panel, got: %v", props["title"])
+	}
+	if _, ok := props["user"]; ok {
+		t.Error("user must not be returned")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected user to be resolved once, got: %d", calls.Load())
+	}
+
+	b = NewBag()
+	b.Set("broken", GoAny(func(ctx context.Context) (any, error) {
+		_, err := Await(ctx, "missing")
+		return nil, err
+	}))
+	_, err = b.GetProps(context.Background())
+	if err == nil {
+		t.Error("awaiting an unknown prop should fail")
+	}
+
+	_, err = Await(context.Background(), "user")
+	if !errors.Is(err, ErrNoResolver) {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
+
+func TestLazy_Get(t *testing.T) {
+	b := NewBag()
+
+	var calls atomic.Int32
+	user := NewLazy(func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "john", nil
+	})
+	// never set in the bag, only awaited
+	role := LazySync(func(ctx context.Context) (string, error) {
+		return "admin", nil
+	})
+	b.Set("user", user)
+	for _, name := range []string{"a", "b", "c"} {
+		b.Set(name, GoAny(func(ctx context.Context) (any, error) {
+			u, err := user.Get(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r, err := role.Get(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return name + ":" + u + ":" + r, nil
+		}))
+	}
+
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["user"] != "john" {
+		t.Errorf("expected john, got: %v", props["user"])
+	}
+	if props["b"] != "b:john:admin" {
+		t.Errorf("expected b:john:admin, got: %v", props["b"])
+	}
+	if _, ok := props["role"]; ok {
+		t.Error("role must not be returned")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected user to be resolved once, got: %d", calls.Load())
+	}
+
+	_, err = user.Get(context.Background())
+	if !errors.Is(err, ErrNoResolver) {
+		t.Errorf("expected ErrNoResolver, got: %v", err)
+	}
+}
+
+func TestLazy_Cycle(t *testing.T) {
+	b := NewBag()
+
+	var a, c *Lazy[int]
+	a = NewLazy(func(ctx context.Context) (int, error) {
+		v, err := c.Get(ctx)
+		return v + 1, err
+	})
+	c = NewLazy(func(ctx context.Context) (int, error) {
+		v, err := a.Get(ctx)
+		return v + 1, err
+	})
+	b.Set("a", a)
+	b.Set("c", c)
+
+	self := NewBag()
+	self.Set("self", LazySync(func(ctx context.Context) (any, error) {
+		return Await(ctx, "self")
+	}))
+
+	for _, bag := range []*Bag{b, self} {
+		done := make(chan error, 1)
+		go func() {
+			_, err := bag.GetProps(context.Background())
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrPropCycle) {
+				t.Errorf("expected ErrPropCycle, got: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("props awaiting each other deadlocked")
+		}
+	}
+}
+
+func TestBag_Memo(t *testing.T) {
+	b := NewBag()
+
+	var calls atomic.Int32
+	fetchUser := func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		return "john", nil
+	}
+	// set by a middleware and again by the handler
+	b.Set("auth", Memo("user", fetchUser))
+	b.Set("user", Memo("user", fetchUser))
+
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["auth"] != "john" || props["user"] != "john" {
+		t.Errorf("expected john for both props, got: %v", props)
+	}
+
+	// the memo lasts for the request, also across renders
+	b.Checkpoint()
+	b.Set("again", Memo("user", fetchUser))
+	_, err = b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected user to be resolved once, got: %d", calls.Load())
+	}
+
+	b.Empty()
+	b.Set("user", Memo("user", fetchUser))
+	_, err = b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected a new request to resolve user again, got: %d", calls.Load())
+	}
+}
+
+func TestBag_MemoContextError(t *testing.T) {
+	var calls atomic.Int32
+	fetchUser := func(ctx context.Context) (any, error) {
+		if calls.Add(1) == 1 {
+			return nil, context.Canceled
+		}
+		return "john", nil
+	}
+
+	b := NewBag()
+	b.Set("user", Memo("user", fetchUser))
+	_, err := b.GetProps(context.Background())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+
+	// a later render of the same request resolves the memo again
+	b.Checkpoint()
+	b.Set("user", Memo("user", fetchUser))
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["user"] != "john" {
+		t.Errorf("expected john, got: %v", props["user"])
+	}
+}
+
+func TestBag_MemoAfterRequest(t *testing.T) {
+	release := make(chan struct{})
+	awaited := make(chan error, 1)
+
+	b := NewBag()
+	b.Set("user", Memo("user", func(ctx context.Context) (any, error) {
+		return "alice", nil
+	}))
+	b.Set("slow", GoAny(func(ctx context.Context) (any, error) {
+		<-release
+		_, err := Await(ctx, "user")
+		awaited <- err
+		return nil, err
+	}).Timeout(10*time.Millisecond))
+	b.Except([]string{"user"})
+	_, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bag serves the next request while the timed out fn of the previous one is still running
+	b.Empty()
+	close(release)
+	if err := <-awaited; !errors.Is(err, ErrResolverDone) {
+		t.Errorf("expected ErrResolverDone, got: %v", err)
+	}
+
+	b.Set("user", Memo("user", func(ctx context.Context) (any, error) {
+		return "bob", nil
+	}))
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["user"] != "bob" {
+		t.Errorf("expected bob, got: %v", props["user"])
+	}
+}
+
+func TestSharedCache(t *testing.T) {
+	cache := NewSharedCache(time.Minute, 10)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	stats := func(ctx context.Context) (any, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	results := make(chan any, 3)
+	for range 3 {
+		go func() {
+			b := NewBag().SetSharedCache(cache)
+			b.Set("stats", Memo("stats", stats).Shared())
+			props, err := b.GetProps(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			results <- props["stats"]
+		}()
+	}
+
+	// give the requests time to join the running resolver
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for range 3 {
+		if v := <-results; v != 42 {
+			t.Errorf("expected 42, got: %v", v)
+		}
+	}
+
+	b := NewBag().SetSharedCache(cache)
+	b.Set("stats", Memo("stats", stats).Shared())
+	_, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected stats to be resolved once, got: %d", calls.Load())
+	}
+
+	// not shared, only memoized per request
+	b = NewBag().SetSharedCache(cache)
+	b.Set("stats", Memo("stats", stats))
+	_, err = b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected unshared memo to resolve again, got: %d", calls.Load())
+	}
+}
+
+func TestSharedCache_Detached(t *testing.T) {
+	type requestKey struct{}
+	ctx := context.WithValue(context.Background(), requestKey{}, "request")
+
+	b := NewBag().SetSharedCache(NewSharedCache(time.Minute, 1))
+	b.Set("user", "john")
+	b.Set("stats", Memo("stats", func(ctx context.Context) (any, error) {
+		if ctx.Value(requestKey{}) != nil {
+			t.Error("expected the shared fn not to see the request values")
+		}
+		_, err := Await(ctx, "user")
+		if !errors.Is(err, ErrNoResolver) {
+			t.Errorf("expected ErrNoResolver, got: %v", err)
+		}
+		return 42, nil
+	}).Shared())
+
+	props, err := b.GetProps(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["stats"] != 42 {
+		t.Errorf("expected 42, got: %v", props["stats"])
+	}
+}
+
+func TestBag_Panic(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	for name, p := range map[string]*LazyProp{
+		"async": GoAny(func(ctx context.Context) (any, error) {
+			panic("boom")
+		}),
+		"sync": NewLazyProp(func(ctx context.Context) (any, error) {
+			panic("boom")
+		}, false, true),
+		"shared": Memo("shared", func(ctx context.Context) (any, error) {
+			panic("boom")
+		}).Shared(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			logs.Reset()
+			b := NewBag().SetLogger(logger).SetSharedCache(NewSharedCache(time.Minute, 1))
+			b.Set(name, p)
+			b.Set("ok", GoAny(func(ctx context.Context) (any, error) {
+				return true, nil
+			}))
+
+			_, err := b.GetProps(context.Background())
+			var pErr *PanicError
+			if !errors.As(err, &pErr) {
+				t.Fatalf("expected a PanicError, got: %v", err)
+			}
+			if pErr.Prop != name || pErr.Value != "boom" {
+				t.Errorf("unexpected panic error: %v", pErr)
+			}
+			if !bytes.Contains(pErr.Stack, []byte("bag_test.go")) {
+				t.Errorf("expected the stack of the panic, got: %s", pErr.Stack)
+			}
+			if !bytes.Contains(logs.Bytes(), []byte("recovered prop panic")) {
+				t.Errorf("expected the panic to be logged, got: %s", logs.String())
+			}
+		})
+	}
+
+	// a panic is an error like any other, the fallback and timeout results still apply
+	logs.Reset()
+	b := NewBag().SetLogger(logger)
+	b.Set("broken", GoAny(func(ctx context.Context) (any, error) {
+		panic("boom")
+	}).Fallback("fallback"))
+	b.Set("timeout", GoAny(func(ctx context.Context) (any, error) {
+		panic("boom")
+	}).Timeout(time.Second))
+	props, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["broken"] != "fallback" {
+		t.Errorf("expected fallback, got: %v", props["broken"])
+	}
+	if res, ok := props["timeout"].(Result[any]); !ok || res.Error == nil {
+		t.Errorf("expected a Result error, got: %v", props["timeout"])
+	}
+	if !bytes.Contains(logs.Bytes(), []byte(`prop=timeout`)) {
+		t.Errorf("expected the panic to be logged, got: %s", logs.String())
+	}
+}
+
+// chanWriter sends every write to the channel, so a test can wait on a log line written from another goroutine
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestBag_PanicAfterRequest(t *testing.T) {
+	logs := make(chanWriter, 1)
+	release := make(chan struct{})
+
+	b := NewBag().SetLogger(slog.New(slog.NewTextHandler(logs, nil)))
+	b.Set("slow", GoAny(func(ctx context.Context) (any, error) {
+		<-release
+		panic("boom")
+	}).Timeout(10*time.Millisecond))
+	_, err := b.GetProps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bag goes back to the pool while the timed out fn is still running
+	b.Empty()
+	close(release)
+
+	select {
+	case line := <-logs:
+		if !strings.Contains(line, "prop=slow") {
+			t.Errorf("expected the panic to be logged for the prop, got: %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the panic to be logged to the logger of the request")
+	}
+}
@@ -0,0 +1,76 @@
+package prop
+
+import (
+	"context"
+	"fmt"
+)
+
+// Lazy is a typed handle to a lazy prop, other props in the same bag can await its value with Get.
+//
+//	user := prop.NewLazy(fetchUser)
+//	bag.Set("user", user)
+//	bag.Set("team", prop.GoAny(func(ctx context.Context) (any, error) {
+//		u, err := user.Get(ctx)
+//		...
+//	}))
+//
+// The fn is evaluated at most once per GetProps call, also when the handle is awaited without being set in the bag.
+type Lazy[T any] struct {
+	prop *LazyProp
+}
+
+// NewLazy creates a handle to a prop that is resolved concurrently, see LazySync and LazyDeferred for the other kinds
+func NewLazy[T any](fn func(ctx context.Context) (T, error)) *Lazy[T] {
+	return newLazy(fn, false, false)
+}
+
+// LazySync creates a handle to a prop that is resolved sequentially with the other sync props
+func LazySync[T any](fn func(ctx context.Context) (T, error)) *Lazy[T] {
+	return newLazy(fn, false, true)
+}
+
+// LazyDeferred creates a handle to a prop that is deferred to be loaded by inertia in a separate request
+func LazyDeferred[T any](fn func(ctx context.Context) (T, error)) *Lazy[T] {
+	return newLazy(fn, true, false)
+}
+
+func newLazy[T any](fn func(ctx context.Context) (T, error), deferred, sync bool) *Lazy[T] {
+	return &Lazy[T]{
+		prop: NewLazyProp(func(ctx context.Context) (any, error) {
+			return fn(ctx)
+		}, deferred, sync),
+	}
+}
+
+// Prop returns the underlying LazyProp, e.g. to set a Group, Timeout or Fallback
+func (l *Lazy[T]) Prop() *LazyProp {
+	return l.prop
+}
+
+func (l *Lazy[T]) lazyProp() *LazyProp {
+	return l.prop
+}
+
+// Get awaits the resolved value, it can only be called from another prop fn of the same bag.
+//
+// A nil Fallback results in the zero value, a Fallback or error Result of a different type in an error.
+func (l *Lazy[T]) Get(ctx context.Context) (T, error) {
+	var zero T
+	r, ok := ctx.Value(resolverKey{}).(*resolver)
+	if !ok {
+		return zero, ErrNoResolver
+	}
+	rp := r.lookup(l.prop)
+	val, err := r.await(ctx, rp)
+	if err != nil {
+		return zero, fmt.Errorf("await prop %q: %w", rp.name, err)
+	}
+	if val == nil {
+		return zero, nil
+	}
+	v, ok := val.(T)
+	if !ok {
+		return zero, fmt.Errorf("await prop %q: resolved to %T instead of %T", rp.name, val, zero)
+	}
+	return v, nil
+}
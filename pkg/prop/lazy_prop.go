@@ -2,6 +2,9 @@ package prop
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
+	"time"
 )
 
 type LazyProp struct {
@@ -9,6 +12,13 @@ type LazyProp struct {
 	fn       LazyPropFn
 	sync     bool
 	deferred bool
+
+	timeout     time.Duration
+	fallback    any
+	hasFallback bool
+
+	memoKey string
+	shared  bool
 }
 
 type LazyPropFn = func(ctx context.Context) (any, error)
@@ -31,6 +41,89 @@ func (p *LazyProp) Group(name string) *LazyProp {
 	return p
 }
 
+// Timeout limits how long the prop may take to resolve, the context passed to the fn is cancelled after d.
+//
+// A prop that times out or errors resolves to the Fallback, or a Result error without one, instead of failing the page.
+// A fn that keeps running after the timeout can no longer await other props, and Bag.Abandoned reports it until it
+// returns.
+func (p *LazyProp) Timeout(d time.Duration) *LazyProp {
+	p.timeout = d
+	return p
+}
+
+// Fallback is used as the value when the prop errors or exceeds its Timeout, instead of failing the page
+func (p *LazyProp) Fallback(value any) *LazyProp {
+	p.fallback = value
+	p.hasFallback = true
+	return p
+}
+
 func (p *LazyProp) IsDeferred() bool {
 	return p.deferred
 }
+
+var errPropTimeout = errors.New("prop timed out")
+
+// resolve evaluates the fn, enforcing the timeout and applying the fallback
+func (p *LazyProp) resolve(ctx context.Context) (any, error) {
+	return p.resolveFn(ctx, p.fn)
+}
+
+// resolveFn evaluates fn in place of the prop fn, enforcing the timeout and applying the fallback
+func (p *LazyProp) resolveFn(ctx context.Context, fn LazyPropFn) (any, error) {
+	if p.timeout <= 0 && !p.hasFallback {
+		return callFn(ctx, fn)
+	}
+
+	val, err := p.run(ctx, fn)
+	if err == nil {
+		return val, nil
+	}
+
+	// the page itself is failing or cancelled, there is no point in falling back
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	if p.hasFallback {
+		return p.fallback, nil
+	}
+	return Err[any]("could not resolve prop", err), nil
+}
+
+// run evaluates fn without waiting on it for longer than the timeout. A fn ignoring the cancelled context is abandoned,
+// it is counted as running in the bag until it returns so the request state it can reach is not reused.
+func (p *LazyProp) run(ctx context.Context, fn LazyPropFn) (any, error) {
+	if p.timeout <= 0 {
+		return callFn(ctx, fn)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, p.timeout, errPropTimeout)
+	defer cancel()
+
+	type result struct {
+		val any
+		err error
+	}
+	// buffered so the goroutine can finish after we stopped waiting on it
+	done := make(chan result, 1)
+	var running *atomic.Int32
+	if r, ok := ctx.Value(resolverKey{}).(*resolver); ok {
+		running = r.running
+		running.Add(1)
+	}
+	go func() {
+		if running != nil {
+			defer running.Add(-1)
+		}
+		val, err := callFn(ctx, fn)
+		done <- result{val, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.val, res.err
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
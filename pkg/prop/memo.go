@@ -0,0 +1,200 @@
+package prop
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Memo creates an async prop that resolves once per request for the key, even when it is set several times,
+// e.g. by a middleware and the page handler. The first prop with the key to be evaluated decides the result,
+// including its Timeout and Fallback.
+//
+// Mark it Shared to also deduplicate the fn across concurrent requests.
+func Memo(key string, fn LazyPropFn) *LazyProp {
+	p := NewLazyProp(fn, false, false)
+	p.memoKey = key
+	return p
+}
+
+// Shared deduplicates the fn of a Memo prop across requests using the SharedCache of the bag, identical keys are only
+// resolved once at a time and successful results are cached for the ttl of the cache. Without a cache, see
+// Bag.SetSharedCache, the prop is only memoized per request.
+//
+// Only use this for values that do not depend on the request, like global stats. The fn runs detached from the
+// request that started it, its ctx carries none of the request values so it can not Await other props either.
+func (p *LazyProp) Shared() *LazyProp {
+	p.shared = true
+	return p
+}
+
+// memoTable holds the Memo results of a request, the bag gets a new one for every request so a resolver of an
+// earlier request can not write into it
+type memoTable struct {
+	lock    sync.Mutex
+	entries map[string]*memoEntry
+}
+
+func newMemoTable() *memoTable {
+	return &memoTable{entries: make(map[string]*memoEntry)}
+}
+
+// entry returns the entry for the key, creating it on first use
+func (m *memoTable) entry(key string) *memoEntry {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &memoEntry{}
+		m.entries[key] = entry
+	}
+	return entry
+}
+
+type memoEntry struct {
+	lock     sync.Mutex
+	resolved bool
+	val      any
+	err      error
+}
+
+// resolveLazy resolves the prop, going through the request memo and the shared cache for Memo props.
+//
+// A result failing with a context error is not kept, it says nothing about the prop and a later render of the same
+// request should try again.
+func (r *resolver) resolveLazy(ctx context.Context, p *LazyProp) (any, error) {
+	if p.memoKey == "" {
+		return p.resolve(ctx)
+	}
+
+	entry := r.memo.entry(p.memoKey)
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+	if entry.resolved {
+		return entry.val, entry.err
+	}
+
+	fn := p.fn
+	if p.shared && r.sharedCache != nil {
+		fn = func(ctx context.Context) (any, error) {
+			return r.sharedCache.do(ctx, p.memoKey, p.fn)
+		}
+	}
+	val, err := p.resolveFn(ctx, fn)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return val, err
+	}
+	entry.resolved = true
+	entry.val, entry.err = val, err
+	return val, err
+}
+
+type sharedCacheEntry struct {
+	key     string
+	val     any
+	expires time.Time
+}
+
+// SharedCache deduplicates Shared Memo props across requests, see NewSharedCache
+type SharedCache struct {
+	group singleflight.Group
+
+	lock       sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+// NewSharedCache creates a cache keeping at most maxEntries successful results for ttl, a ttl <= 0 only
+// deduplicates resolvers running at the same time.
+func NewSharedCache(ttl time.Duration, maxEntries int) *SharedCache {
+	return &SharedCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// do returns the cached value or joins the running fn for the key. The fn is not cancelled with the request that
+// started it since other requests may be waiting on it, callers still stop waiting when their ctx is done.
+//
+// The fn gets a fresh context instead of the request's, which holds the resolver and through it the pooled bag.
+func (c *SharedCache) do(ctx context.Context, key string, fn LazyPropFn) (any, error) {
+	if val, ok := c.get(key); ok {
+		return val, nil
+	}
+
+	call, _ := ctx.Value(propCallKey{}).(propCall)
+	detached := context.WithValue(context.Background(), propCallKey{}, call)
+	ch := c.group.DoChan(key, func() (any, error) {
+		// singleflight runs this in its own goroutine, which re-panics
+		val, err := callFn(detached, fn)
+		if err == nil {
+			c.set(key, val)
+		}
+		return val, err
+	})
+
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+func (c *SharedCache) get(key string) (any, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*sharedCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.val, true
+}
+
+func (c *SharedCache) set(key string, val any) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := &sharedCacheEntry{
+		key:     key,
+		val:     val,
+		expires: time.Now().Add(c.ttl),
+	}
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sharedCacheEntry).key)
+	}
+}
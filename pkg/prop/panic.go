@@ -0,0 +1,61 @@
+package prop
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// PanicError is returned for a prop fn that panicked, so a single prop can not take down the process
+type PanicError struct {
+	Prop  string
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("prop %q panicked: %v", e.Prop, e.Value)
+}
+
+// Unwrap returns the panic value when it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// SetLogger sets the logger recovered panics are reported to, slog.Default is used without one
+func (b *Bag) SetLogger(logger *slog.Logger) *Bag {
+	b.logger = logger
+	return b
+}
+
+// propCallKey holds the propCall of the prop being evaluated
+type propCallKey struct{}
+
+// propCall is what a recovered panic is reported with. It is captured when the evaluation starts since the fn may
+// outlive the request, while the pooled bag gets emptied and reused.
+type propCall struct {
+	name   string
+	logger *slog.Logger
+}
+
+// callFn runs fn, recovering a panic into a PanicError, this is needed for every fn that may run in its own goroutine
+func callFn(ctx context.Context, fn LazyPropFn) (val any, err error) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+
+		call, _ := ctx.Value(propCallKey{}).(propCall)
+		pErr := &PanicError{Prop: call.name, Value: rec, Stack: debug.Stack()}
+		logger := call.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Error("recovered prop panic", slog.String("prop", pErr.Prop), slog.Any("panic", rec), slog.String("stack", string(pErr.Stack)))
+		val, err = nil, pErr
+	}()
+	return fn(ctx)
+}
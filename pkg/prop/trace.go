@@ -0,0 +1,25 @@
+package prop
+
+import "context"
+
+// Info describes a lazy prop being resolved
+type Info struct {
+	Name     string
+	Group    string
+	Sync     bool
+	Deferred bool
+}
+
+// Tracer is notified about every lazy prop resolved by GetProps, including props that are only awaited.
+//
+// StartProp is called before the prop fn runs, the returned context is passed to the fn and end is called with
+// the error of the prop once it is resolved.
+type Tracer interface {
+	StartProp(ctx context.Context, info Info) (context.Context, func(err error))
+}
+
+// SetTracer sets the tracer notified about the props resolved by GetProps
+func (b *Bag) SetTracer(t Tracer) *Bag {
+	b.tracer = t
+	return b
+}
@@ -0,0 +1,200 @@
+// Package route keeps a registry of named http.ServeMux patterns, so urls can be built from a route name and params
+// on the server and, through yaigo.TypeGenerator.GenerateRoutes, in the frontend.
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type contextKey int
+
+const routerKey contextKey = iota
+
+// Params are the values for the wildcards in a route pattern, params without a wildcard are added to the query
+type Params = map[string]any
+
+// Route is a named http.ServeMux pattern
+type Route struct {
+	Name   string `json:"-"`
+	Method string `json:"method,omitempty"`
+	Host   string `json:"host,omitempty"`
+	Path   string `json:"path"`
+}
+
+var wildcardRegex = regexp.MustCompile(`\{([^}]*)}`)
+
+// Params returns the wildcard names in the path, e.g. "id" for /users/{id}
+func (route Route) Params() []string {
+	var params []string
+	for _, m := range wildcardRegex.FindAllStringSubmatch(route.Path, -1) {
+		name := strings.TrimSuffix(m[1], "...")
+		if name != "$" {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// URL builds the path of the route with the params
+func (route Route) URL(params Params) (string, error) {
+	used := make(map[string]struct{})
+	var missing []string
+	path := wildcardRegex.ReplaceAllStringFunc(route.Path, func(wildcard string) string {
+		name := wildcard[1 : len(wildcard)-1]
+		if name == "$" {
+			return ""
+		}
+		name, rest := strings.CutSuffix(name, "...")
+
+		value, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return ""
+		}
+		used[name] = struct{}{}
+
+		str := fmt.Sprint(value)
+		if !rest {
+			return url.PathEscape(str)
+		}
+		segments := strings.Split(str, "/")
+		for i, s := range segments {
+			segments[i] = url.PathEscape(s)
+		}
+		return strings.Join(segments, "/")
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("route %q: missing params %s", route.Name, strings.Join(missing, ", "))
+	}
+
+	query := url.Values{}
+	for k, v := range params {
+		if _, ok := used[k]; !ok {
+			query.Set(k, fmt.Sprint(v))
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// Router wraps http.ServeMux to record named patterns so urls can be built from them
+type Router struct {
+	mux    *http.ServeMux
+	lock   sync.RWMutex
+	routes map[string]Route
+}
+
+func NewRouter() *Router {
+	return &Router{
+		mux:    http.NewServeMux(),
+		routes: make(map[string]Route),
+	}
+}
+
+// Handle registers the handler for the pattern like http.ServeMux, an empty name registers an unnamed route.
+//
+// Panics when the name is already registered.
+func (rt *Router) Handle(name string, pattern string, handler http.Handler) {
+	rt.mux.Handle(pattern, handler)
+	if name == "" {
+		return
+	}
+
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	if _, ok := rt.routes[name]; ok {
+		panic(fmt.Sprintf("route: %q already registered", name))
+	}
+	rt.routes[name] = parsePattern(name, pattern)
+}
+
+// HandleFunc registers the handler func for the pattern like http.ServeMux
+func (rt *Router) HandleFunc(name string, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	rt.Handle(name, pattern, http.HandlerFunc(handler))
+}
+
+// ServeHTTP dispatches to the mux, making the router available to URL through the request context
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r.WithContext(WithRouter(r.Context(), rt)))
+}
+
+// Route returns the named route
+func (rt *Router) Route(name string) (Route, bool) {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	route, ok := rt.routes[name]
+	return route, ok
+}
+
+// Routes returns all named routes sorted by name
+func (rt *Router) Routes() []Route {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	routes := make([]Route, 0, len(rt.routes))
+	for _, route := range rt.routes {
+		routes = append(routes, route)
+	}
+	slices.SortFunc(routes, func(a, b Route) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return routes
+}
+
+// Table returns the routes by name, meant to be shared with the frontend as a prop
+func (rt *Router) Table() map[string]Route {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	table := make(map[string]Route, len(rt.routes))
+	for name, route := range rt.routes {
+		table[name] = route
+	}
+	return table
+}
+
+// URL builds the url for the named route
+func (rt *Router) URL(name string, params Params) (string, error) {
+	route, ok := rt.Route(name)
+	if !ok {
+		return "", fmt.Errorf("route %q not found", name)
+	}
+	return route.URL(params)
+}
+
+// WithRouter sets the router used by URL in the context
+func WithRouter(ctx context.Context, rt *Router) context.Context {
+	return context.WithValue(ctx, routerKey, rt)
+}
+
+// URL builds the url for the named route using the router serving the request
+func URL(ctx context.Context, name string, params Params) (string, error) {
+	rt, ok := ctx.Value(routerKey).(*Router)
+	if !ok {
+		return "", fmt.Errorf("route %q: no router in context", name)
+	}
+	return rt.URL(name, params)
+}
+
+// parsePattern splits a "[METHOD ][HOST]/[PATH]" http.ServeMux pattern
+func parsePattern(name string, pattern string) Route {
+	route := Route{Name: name}
+	rest := strings.TrimSpace(pattern)
+	if method, after, ok := strings.Cut(rest, " "); ok {
+		route.Method = method
+		rest = strings.TrimLeft(after, " \t")
+	}
+	if i := strings.Index(rest, "/"); i > 0 {
+		route.Host = rest[:i]
+		rest = rest[i:]
+	}
+	route.Path = rest
+	return route
+}
@@ -0,0 +1,74 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRoute_URL(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		params   Params
+		expected string
+		err      bool
+	}{
+		{"GET /users/{id}", Params{"id": 12}, "/users/12", false},
+		{"GET /users/{id}", Params{"id": "a b/c"}, "/users/a%20b%2Fc", false},
+		{"GET /users/{id}", Params{"id": 1, "tab": "posts"}, "/users/1?tab=posts", false},
+		{"GET /users/{id}", nil, "", true},
+		{"/files/{path...}", Params{"path": "a/b c"}, "/files/a/b%20c", false},
+		{"GET example.com/{$}", nil, "/", false},
+	}
+
+	for _, tt := range tests {
+		route := parsePattern("test", tt.pattern)
+		url, err := route.URL(tt.params)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: unexpected error: %v", tt.pattern, err)
+		}
+		if url != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.pattern, tt.expected, url)
+		}
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	route := parsePattern("users.show", "GET example.com/users/{id}/{rest...}")
+	if route.Method != "GET" || route.Host != "example.com" || route.Path != "/users/{id}/{rest...}" {
+		t.Errorf("unexpected route: %+v", route)
+	}
+	if !slices.Equal(route.Params(), []string{"id", "rest"}) {
+		t.Errorf("unexpected params: %v", route.Params())
+	}
+}
+
+func TestRouter(t *testing.T) {
+	rt := NewRouter()
+	var built string
+	rt.HandleFunc("users.show", "GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		built, err = URL(r.Context(), "users.show", Params{"id": r.PathValue("id")})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	rt.HandleFunc("", "GET /health", func(w http.ResponseWriter, r *http.Request) {})
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	if built != "/users/42" {
+		t.Errorf("unexpected url from context: %q", built)
+	}
+
+	if routes := rt.Routes(); len(routes) != 1 || routes[0].Name != "users.show" {
+		t.Errorf("unnamed routes should not be recorded: %v", routes)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	rt.HandleFunc("users.show", "GET /people/{id}", func(w http.ResponseWriter, r *http.Request) {})
+}
@@ -2,17 +2,21 @@ package vite
 
 import (
 	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"slices"
 )
 
 type viteManifestData = map[string]ManifestItem
 
 type Manifest struct {
-	data viteManifestData
+	data      viteManifestData
+	integrity map[string]string
 }
 
 type ManifestItem struct {
@@ -21,6 +25,8 @@ type ManifestItem struct {
 	Src     string   `json:"src"`
 	IsEntry bool     `json:"isEntry"`
 	Css     []string `json:"css"`
+	Assets  []string `json:"assets"`
+	Imports []string `json:"imports"`
 }
 
 func FromDistFS(frontend fs.FS) (manifest *Manifest, err error) {
@@ -47,7 +53,7 @@ func FromJSON(r io.Reader) (*Manifest, error) {
 	}
 
 	return &Manifest{
-		data,
+		data: data,
 	}, nil
 }
 
@@ -67,3 +73,102 @@ func (m *Manifest) GetItem(name string) (ManifestItem, error) {
 	}
 	return entry, nil
 }
+
+// Files returns every file in the build output referenced by the manifest
+func (m *Manifest) Files() []string {
+	seen := make(map[string]struct{})
+	var files []string
+	for _, item := range m.data {
+		for _, list := range [][]string{{item.File}, item.Css, item.Assets} {
+			for _, file := range list {
+				if _, ok := seen[file]; ok || file == "" {
+					continue
+				}
+				seen[file] = struct{}{}
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// EntryFiles returns the scripts and stylesheets needed by the entry, including the ones of imported chunks
+func (m *Manifest) EntryFiles(name string) (scripts []string, styles []string, err error) {
+	seen := make(map[string]struct{})
+	var walk func(name string) error
+	walk = func(name string) error {
+		if _, ok := seen[name]; ok {
+			return nil
+		}
+		seen[name] = struct{}{}
+
+		item, err := m.GetItem(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		scripts = append(scripts, item.File)
+		for _, css := range item.Css {
+			if !slices.Contains(styles, css) {
+				styles = append(styles, css)
+			}
+		}
+		for _, imported := range item.Imports {
+			err := walk(imported)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scripts, styles, nil
+}
+
+// ComputeIntegrity calculates the sha384 Subresource Integrity hashes for every file in the manifest
+func (m *Manifest) ComputeIntegrity(frontend fs.FS) error {
+	integrity := make(map[string]string)
+	for _, item := range m.data {
+		for _, file := range append([]string{item.File}, item.Css...) {
+			if _, ok := integrity[file]; ok {
+				continue
+			}
+			hash, err := fileIntegrity(frontend, file)
+			if err != nil {
+				return fmt.Errorf("hashing %s: %w", file, err)
+			}
+			integrity[file] = hash
+		}
+	}
+	m.integrity = integrity
+	return nil
+}
+
+// Integrity returns the Subresource Integrity hash for a file, requires ComputeIntegrity to be called first
+func (m *Manifest) Integrity(file string) (string, bool) {
+	hash, ok := m.integrity[file]
+	return hash, ok
+}
+
+func fileIntegrity(frontend fs.FS, file string) (hash string, err error) {
+	f, err := frontend.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		cErr := f.Close()
+		if cErr != nil && err == nil {
+			err = cErr
+		}
+	}()
+
+	h := sha512.New384()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return "sha384-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,52 @@
+package vite
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testManifest = `{
+	"src/main.ts": {"file": "assets/main.js", "src": "src/main.ts", "isEntry": true, "css": ["assets/main.css"]},
+	"src/admin.ts": {"file": "assets/admin.js", "src": "src/admin.ts", "isEntry": true, "css": ["assets/main.css"]}
+}`
+
+func TestManifest_ComputeIntegrity(t *testing.T) {
+	frontend := fstest.MapFS{
+		"assets/main.js":  &fstest.MapFile{Data: []byte("main")},
+		"assets/admin.js": &fstest.MapFile{Data: []byte("admin")},
+		"assets/main.css": &fstest.MapFile{Data: []byte("body{}")},
+	}
+	m, err := FromJSON(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.ComputeIntegrity(frontend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha512.Sum384([]byte("main"))
+	expected := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+	if hash, ok := m.Integrity("assets/main.js"); !ok || hash != expected {
+		t.Errorf("unexpected integrity for main.js: %s", hash)
+	}
+	if _, ok := m.Integrity("assets/main.css"); !ok {
+		t.Error("css integrity missing")
+	}
+}
+
+func TestManifest_ComputeIntegrityMissingFile(t *testing.T) {
+	m, err := FromJSON(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.ComputeIntegrity(fstest.MapFS{})
+	if err == nil {
+		t.Error("expected an error for missing files")
+	}
+}
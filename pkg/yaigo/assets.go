@@ -0,0 +1,147 @@
+package yaigo
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// precompressed variants looked up next to the asset, in order of preference
+var assetEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// AssetHandler serves the built assets listed in the vite manifest from the frontend filesystem with immutable
+// cache headers, using precompressed .br and .gz variants when present. Anything else, like the manifest itself,
+// returns a 404.
+//
+// The request path has to match the file in the build output, e.g. mux.Handle("GET /assets/", config.AssetHandler())
+// or wrapped in http.StripPrefix when using WithAssetBaseURL with a path prefix.
+func (s *Config) AssetHandler() http.Handler {
+	files := make(map[string]assetFile)
+	for _, name := range s.manifest.Files() {
+		file := assetFile{contentType: mime.TypeByExtension(path.Ext(name))}
+		if file.contentType == "" {
+			// the type has to be set explicitly, ServeContent would sniff the compressed bytes of a variant
+			file.contentType = "application/octet-stream"
+		}
+		for _, enc := range assetEncodings {
+			if _, err := fs.Stat(s.frontend, name+enc.extension); err == nil {
+				file.precompressed = true
+			}
+		}
+		files[name] = file
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		file, ok := files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		if file.precompressed {
+			acceptEncoding := r.Header.Get("Accept-Encoding")
+			for _, enc := range assetEncodings {
+				if !acceptsEncoding(acceptEncoding, enc.encoding) {
+					continue
+				}
+				if serveAsset(w, r, s.frontend, file, name+enc.extension, enc.encoding) {
+					return
+				}
+			}
+		}
+
+		if !serveAsset(w, r, s.frontend, file, name, "") {
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// assetFile holds what is known about a manifest file when the handler is created
+type assetFile struct {
+	contentType string
+	// precompressed is set when at least one encoded variant exists, only then the response depends on Accept-Encoding
+	precompressed bool
+}
+
+// serveAsset serves name from frontend, returns false if the file could not be opened.
+// The headers are only set once the file is found, so a 404 does not carry them.
+func serveAsset(w http.ResponseWriter, r *http.Request, frontend fs.FS, file assetFile, name string, encoding string) bool {
+	f, err := frontend.Open(name)
+	if err != nil {
+		return false
+	}
+	defer func(f fs.File) {
+		_ = f.Close()
+	}(f)
+
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		return false
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return false
+		}
+		content = bytes.NewReader(data)
+	}
+
+	h := w.Header()
+	h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	h.Set("Content-Type", file.contentType)
+	if file.precompressed {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if encoding != "" {
+		h.Set("Content-Encoding", encoding)
+	}
+
+	// modtime is left out on purpose, embedded files do not have one and the names are content hashed anyway
+	http.ServeContent(w, r, name, time.Time{}, content)
+	return true
+}
+
+// acceptsEncoding reports if the Accept-Encoding header allows the encoding, either by name or through "*".
+// An entry with q=0 or an invalid q value refuses it.
+func acceptsEncoding(header string, encoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.EqualFold(name, encoding):
+			return qualityAllows(params)
+		case name == "*":
+			wildcard = qualityAllows(params)
+		}
+	}
+	return wildcard
+}
+
+// qualityAllows reports if the q parameter in params is above 0, it defaults to 1 when missing
+func qualityAllows(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err == nil && q > 0
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package yaigo
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConfig_AssetHandler(t *testing.T) {
+	frontend := fstest.MapFS{}
+	for k, v := range testFrontend {
+		frontend[k] = v
+	}
+	frontend[".vite/manifest.json"] = &fstest.MapFile{Data: []byte(`{
+	"src/main.ts": {"file": "assets/main-abc123.js", "src": "src/main.ts", "isEntry": true, "css": ["assets/main-def456.css"], "assets": ["assets/model-789.unknownext"]}
+}`)}
+	frontend["assets/main-abc123.js.br"] = &fstest.MapFile{Data: []byte("brotli")}
+	frontend["assets/model-789.unknownext"] = &fstest.MapFile{Data: []byte("model")}
+	frontend["assets/model-789.unknownext.gz"] = &fstest.MapFile{Data: []byte("\x1f\x8b<html>")}
+
+	config, err := New(testTemplate, frontend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := config.AssetHandler()
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		status         int
+		body           string
+		contentType    string
+		encoding       string
+		vary           string
+	}{
+		{"plain", "/assets/main-def456.css", "gzip, br", 200, "body{}", "text/css", "", ""},
+		{"brotli", "/assets/main-abc123.js", "gzip, br", 200, "brotli", "javascript", "br", "Accept-Encoding"},
+		{"brotli refused", "/assets/main-abc123.js", "br;q=0", 200, "console.log('hi')", "javascript", "", "Accept-Encoding"},
+		{"gzip unknown type", "/assets/model-789.unknownext", "gzip", 200, "\x1f\x8b<html>", "application/octet-stream", "gzip", "Accept-Encoding"},
+		{"manifest", "/.vite/manifest.json", "gzip, br", 404, "", "", "", ""},
+		{"unknown", "/assets/other.js", "gzip, br", 404, "", "", "", ""},
+		{"traversal", "/assets/../.vite/manifest.json", "gzip, br", 404, "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.path, nil)
+			r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if vary := rec.Header().Get("Vary"); vary != tt.vary {
+				t.Errorf("unexpected vary: %s", vary)
+			}
+			if tt.status != 200 {
+				return
+			}
+			if rec.Body.String() != tt.body {
+				t.Errorf("unexpected body: %s", rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, tt.contentType) {
+				t.Errorf("unexpected content type: %s", ct)
+			}
+			if enc := rec.Header().Get("Content-Encoding"); enc != tt.encoding {
+				t.Errorf("unexpected content encoding: %s", enc)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=31536000, immutable" {
+				t.Errorf("unexpected cache control: %s", cc)
+			}
+		})
+	}
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", false},
+		{"br", true},
+		{"gzip, br", true},
+		{"BR;q=0.5", true},
+		{"br;q=0", false},
+		{"br;q=0.00", false},
+		{"br; q = 0.000", false},
+		{"br;q=invalid", false},
+		{"gzip, *", true},
+		{"*;q=0", false},
+		{"*;q=0.1", true},
+		{"br;q=0, *", false},
+		{"*;q=0, br", true},
+	}
+
+	for _, tt := range tests {
+		if actual := acceptsEncoding(tt.header, "br"); actual != tt.expected {
+			t.Errorf("%q: expected %v, got %v", tt.header, tt.expected, actual)
+		}
+	}
+}
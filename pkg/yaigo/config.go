@@ -1,8 +1,10 @@
 package yaigo
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/tortlewortle/yaigo/pkg/prop"
 	"github.com/tortlewortle/yaigo/pkg/vite"
 	"html/template"
 	"io/fs"
@@ -21,7 +23,10 @@ func New(tfn func(*template.Template) (*template.Template, error), frontend fs.F
 
 	// default opts
 	opts := &ServerOpts{
-		ViteUrl: "",
+		ViteUrl:       "",
+		RootID:        "app",
+		ErrorPage:     "Error",
+		ErrorStatuses: []int{http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable},
 	}
 
 	for _, fn := range optFns {
@@ -38,33 +43,77 @@ func New(tfn func(*template.Template) (*template.Template, error), frontend fs.F
 		return nil, err
 	}
 
-	rootTmpl, err := generateRootTemplate(tfn, manifest, opts)
+	if opts.SRI && opts.ViteUrl == "" {
+		err = manifest.ComputeIntegrity(frontend)
+		if err != nil {
+			return nil, fmt.Errorf("computing asset integrity: %w", err)
+		}
+	}
+
+	rootLayout, err := newLayout(tfn, opts.EarlyHints, manifest, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	ssrTransport := http.DefaultTransport.(*http.Transport).Clone()
-	ssrTransport.MaxIdleConns = 100
-	ssrTransport.MaxConnsPerHost = 100
-	ssrTransport.MaxIdleConnsPerHost = 100
+	layouts := make(map[string]*layout, len(opts.Layouts))
+	for name, lo := range opts.Layouts {
+		layouts[name], err = newLayout(lo.tfn, lo.entries, manifest, opts)
+		if err != nil {
+			return nil, fmt.Errorf("layout %q: %w", name, err)
+		}
+	}
+	for _, lp := range opts.LayoutPrefixes {
+		if _, ok := layouts[lp.name]; !ok {
+			return nil, fmt.Errorf("prefix %q uses unknown layout %q", lp.prefix, lp.name)
+		}
+	}
+
+	ssrRenderer := opts.SSRRenderer
+	if ssrRenderer == nil && opts.SSRServerUrl != "" {
+		ssrRenderer = NewHTTPSSRRenderer(opts.SSRServerUrl, opts.SSRTimeout)
+	}
 
 	if opts.Logger == nil {
 		opts.Logger = slog.Default()
 	}
+	if opts.Hooks == nil {
+		opts.Hooks = NoopHooks{}
+	}
 
 	server := &Config{
 		typeGenerator:   nil,
 		manifestVersion: version,
-		ssrHTTPClient: &http.Client{
-			Timeout:   opts.SSRTimeout,
-			Transport: ssrTransport,
-		},
-		ssrURL:       opts.SSRServerUrl,
-		rootTemplate: rootTmpl,
+		manifest:        manifest,
+		frontend:        frontend,
+		ssrRenderer:     ssrRenderer,
+		ssrCache:        nil,
+		rootLayout:      rootLayout,
+		layouts:         layouts,
+		layoutPrefixes:  opts.LayoutPrefixes,
+
+		viteDevUrl:    opts.ViteUrl,
+		reactRefresh:  opts.ReactRefresh,
+		streamHTML:    opts.StreamHTML,
+		pageScriptTag: opts.PageScript,
+		rootElementID: opts.RootID,
+		nonceFunc:     opts.NonceFunc,
+		earlyHints103: opts.EarlyHints103,
+		logger:        opts.Logger,
+		propLimit:     opts.PropLimit,
+		propCache:     nil,
+		hooks:         opts.Hooks,
+
+		errorComponent: opts.ErrorPage,
+		errorDebug:     opts.ErrorDebug,
+		errorStatuses:  opts.ErrorStatuses,
+	}
+
+	if ssrRenderer != nil && opts.SSRCacheTTL > 0 {
+		server.ssrCache = newSSRCache(opts.SSRCacheTTL, opts.SSRCacheSize)
+	}
 
-		viteDevUrl:   opts.ViteUrl,
-		reactRefresh: opts.ReactRefresh,
-		logger:       opts.Logger,
+	if opts.PropCache {
+		server.propCache = prop.NewSharedCache(opts.PropCacheTTL, opts.PropCacheSize)
 	}
 
 	if opts.TypeGen != nil {
@@ -81,18 +130,42 @@ func New(tfn func(*template.Template) (*template.Template, error), frontend fs.F
 
 type Config struct {
 	manifestVersion string
+	manifest        *vite.Manifest
+	frontend        fs.FS
 
-	rootTemplate *template.Template
+	rootLayout     *layout
+	layouts        map[string]*layout
+	layoutPrefixes []layoutPrefix
 
-	ssrHTTPClient *http.Client
-	ssrURL        string
+	ssrRenderer SSRRenderer
+	ssrCache    *ssrCache
 
 	reactRefresh  bool
+	streamHTML    bool
+	pageScriptTag bool
+	rootElementID string
+	nonceFunc     NonceFunc
+	earlyHints103 bool
 	viteDevUrl    string
 	typeGenerator *TypeGenerator
 	logger        *slog.Logger
+	propLimit     int
+	propCache     *prop.SharedCache
+	hooks         Hooks
+
+	errorComponent string
+	errorDebug     bool
+	errorStatuses  []int
 }
 
 func (s *Config) IsDevMode() bool {
 	return s.viteDevUrl != ""
 }
+
+// Logger returns the logger of the config in the context, or the default logger without one
+func Logger(ctx context.Context) *slog.Logger {
+	if config, ok := ctx.Value(configKey).(*Config); ok {
+		return config.logger
+	}
+	return slog.Default()
+}
@@ -1,17 +1,39 @@
 package yaigo
 
 import (
+	"html/template"
 	"log/slog"
 	"time"
 )
 
 type ServerOpts struct {
-	ViteUrl      string
-	SSRServerUrl string
-	ReactRefresh bool
-	SSRTimeout   time.Duration
-	TypeGen      *TypeGenerator
-	Logger       *slog.Logger
+	ViteUrl        string
+	SSRServerUrl   string
+	ReactRefresh   bool
+	SSRTimeout     time.Duration
+	SSRRenderer    SSRRenderer
+	SSRCacheTTL    time.Duration
+	SSRCacheSize   int
+	StreamHTML     bool
+	PageScript     bool
+	RootID         string
+	NonceFunc      NonceFunc
+	SRI            bool
+	AssetBaseURL   string
+	EarlyHints     []string
+	EarlyHints103  bool
+	Layouts        map[string]layoutOpts
+	LayoutPrefixes []layoutPrefix
+	ErrorPage      string
+	ErrorDebug     bool
+	ErrorStatuses  []int
+	TypeGen        *TypeGenerator
+	Logger         *slog.Logger
+	PropLimit      int
+	PropCache      bool
+	PropCacheTTL   time.Duration
+	PropCacheSize  int
+	Hooks          Hooks
 }
 
 type OptFunc = func(o *ServerOpts)
@@ -24,7 +46,9 @@ func WithViteDevServer(url string, reactRefresh bool) OptFunc {
 	}
 }
 
-// WithSSR enables Config-side rendering using the provided ssr Config url and bundle bundlePath
+// WithSSR enables server-side rendering using the provided ssr server url
+//
+// Both http(s):// and unix:///path/to/ssr.sock urls are supported.
 func WithSSR(url string, timeout time.Duration) OptFunc {
 	return func(o *ServerOpts) {
 		o.SSRServerUrl = url
@@ -32,12 +56,166 @@ func WithSSR(url string, timeout time.Duration) OptFunc {
 	}
 }
 
+// WithSSRRenderer enables server-side rendering using a custom SSRRenderer, takes precedence over WithSSR
+func WithSSRRenderer(renderer SSRRenderer) OptFunc {
+	return func(o *ServerOpts) {
+		o.SSRRenderer = renderer
+	}
+}
+
+// WithSSRCache caches ssr output for identical page objects for ttl, keeping at most maxEntries results
+//
+// Pages carrying per-user data can opt out using Page.NoSSRCache.
+func WithSSRCache(ttl time.Duration, maxEntries int) OptFunc {
+	return func(o *ServerOpts) {
+		o.SSRCacheTTL = ttl
+		o.SSRCacheSize = maxEntries
+	}
+}
+
+// WithHTMLStreaming streams full page responses, writing the root template head before encoding the page object
+// directly into the response instead of buffering the whole document
+func WithHTMLStreaming(stream bool) OptFunc {
+	return func(o *ServerOpts) {
+		o.StreamHTML = stream
+	}
+}
+
+// WithPageScriptTag renders the initial page object in a <script type="application/json"> tag instead of
+// the data-page attribute on the root element, requires inertia v2 with the script element enabled
+func WithPageScriptTag(enabled bool) OptFunc {
+	return func(o *ServerOpts) {
+		o.PageScript = enabled
+	}
+}
+
+// WithRootElementID sets the id of the element inertia mounts on, defaults to "app"
+func WithRootElementID(id string) OptFunc {
+	return func(o *ServerOpts) {
+		o.RootID = id
+	}
+}
+
+// WithCSPNonce generates a Content-Security-Policy nonce for every request, it is applied to the scripts yaigo
+// injects and is available as .Nonce in the root template and through Nonce(ctx)
+func WithCSPNonce() OptFunc {
+	return WithCSPNonceFunc(GenerateNonce)
+}
+
+// WithCSPNonceFunc is WithCSPNonce with a custom nonce provider
+func WithCSPNonceFunc(fn NonceFunc) OptFunc {
+	return func(o *ServerOpts) {
+		o.NonceFunc = fn
+	}
+}
+
+// WithSubresourceIntegrity hashes the built assets on startup and adds integrity and crossorigin attributes
+// to the tags emitted by the viteCSS and viteScript template funcs, ignored when using the vite dev server
+func WithSubresourceIntegrity(enabled bool) OptFunc {
+	return func(o *ServerOpts) {
+		o.SRI = enabled
+	}
+}
+
+// WithAssetBaseURL serves the built assets from a path prefix or CDN instead of the site root,
+// e.g. "/app" or "https://cdn.example.com/app"
+func WithAssetBaseURL(baseURL string) OptFunc {
+	return func(o *ServerOpts) {
+		o.AssetBaseURL = baseURL
+	}
+}
+
+// WithEarlyHints sets Link preload headers for the scripts and stylesheets of the given entries (e.g. "src/main.ts")
+// on full page visits, use WithEarlyHintsResponse to also send them before the props are resolved
+func WithEarlyHints(entries ...string) OptFunc {
+	return func(o *ServerOpts) {
+		o.EarlyHints = entries
+	}
+}
+
+// WithEarlyHintsResponse sends the preload Link headers in a 103 Early Hints response before the props are resolved.
+//
+// The 103 is written to the innermost writer found through Unwrap, only enable it when that is the writer of the
+// net/http server or another one supporting informational responses.
+func WithEarlyHintsResponse(enabled bool) OptFunc {
+	return func(o *ServerOpts) {
+		o.EarlyHints103 = enabled
+	}
+}
+
+// WithLayout registers a named root template, selected using Page.Layout, SetLayout or WithLayoutPrefix.
+//
+// The entries (e.g. "src/admin.ts") are preloaded using early hints, same as WithEarlyHints for the default template.
+func WithLayout(name string, tfn func(*template.Template) (*template.Template, error), entries ...string) OptFunc {
+	return func(o *ServerOpts) {
+		if o.Layouts == nil {
+			o.Layouts = make(map[string]layoutOpts)
+		}
+		o.Layouts[name] = layoutOpts{
+			tfn:     tfn,
+			entries: entries,
+		}
+	}
+}
+
+// WithLayoutPrefix uses the named layout for every request with a path starting with prefix, the longest prefix wins
+func WithLayoutPrefix(prefix string, name string) OptFunc {
+	return func(o *ServerOpts) {
+		o.LayoutPrefixes = append(o.LayoutPrefixes, layoutPrefix{
+			prefix: prefix,
+			name:   name,
+		})
+	}
+}
+
+// WithErrorPage sets the component ErrorMiddleware renders for panics and the given error statuses,
+// debug includes the error and stack trace as props and should only be enabled during development.
+//
+// Defaults to the "Error" component for 403, 404, 500 and 503 responses.
+func WithErrorPage(component string, debug bool, statuses ...int) OptFunc {
+	return func(o *ServerOpts) {
+		o.ErrorPage = component
+		o.ErrorDebug = debug
+		if len(statuses) > 0 {
+			o.ErrorStatuses = statuses
+		}
+	}
+}
+
 func WithTypeGen(gen *TypeGenerator) OptFunc {
 	return func(o *ServerOpts) {
 		o.TypeGen = gen
 	}
 }
 
+// WithPropConcurrency limits how many async props of a request are resolved at the same time, n <= 0 means no limit.
+//
+// Can be overridden per request with SetPropConcurrency.
+func WithPropConcurrency(n int) OptFunc {
+	return func(o *ServerOpts) {
+		o.PropLimit = n
+	}
+}
+
+// WithPropCache deduplicates prop.Memo props marked Shared across concurrent requests and caches their results for
+// ttl, keeping at most maxEntries results. A ttl <= 0 only deduplicates resolvers running at the same time.
+//
+// Without it Shared props are only memoized per request.
+func WithPropCache(ttl time.Duration, maxEntries int) OptFunc {
+	return func(o *ServerOpts) {
+		o.PropCache = true
+		o.PropCacheTTL = ttl
+		o.PropCacheSize = maxEntries
+	}
+}
+
+// WithHooks notifies the hooks about middleware, prop resolution, rendering, ssr fallbacks and typegen runs
+func WithHooks(hooks Hooks) OptFunc {
+	return func(o *ServerOpts) {
+		o.Hooks = hooks
+	}
+}
+
 func WithLogger(logger *slog.Logger) OptFunc {
 	return func(o *ServerOpts) {
 		o.Logger = logger
@@ -13,6 +13,11 @@ const (
 	requestInfoKey
 	bagKey
 	pageDataKey
+	headKey
+	nonceKey
+	layoutKey
+	templateDataKey
+	errorStateKey
 )
 
 // WithConfig sets the *yaigo.Config in the context
@@ -34,3 +39,28 @@ func WithPropBag(ctx context.Context, bag *prop.Bag) context.Context {
 func WithInertiaPage(ctx context.Context, bag *page.InertiaPage) context.Context {
 	return context.WithValue(ctx, pageDataKey, bag)
 }
+
+// WithHead provides the head tags for the root template
+func WithHead(ctx context.Context, head *HeadTags) context.Context {
+	return context.WithValue(ctx, headKey, head)
+}
+
+// WithNonce sets the Content-Security-Policy nonce applied to the tags yaigo renders
+func WithNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, nonceKey, nonce)
+}
+
+// withLayoutSelection provides the layout selected for the request
+func withLayoutSelection(ctx context.Context, sel *layoutSelection) context.Context {
+	return context.WithValue(ctx, layoutKey, sel)
+}
+
+// WithTemplateData provides the map exposed as .Data to the root template
+func WithTemplateData(ctx context.Context, data map[string]any) context.Context {
+	return context.WithValue(ctx, templateDataKey, data)
+}
+
+// withErrorState provides the error state used by ErrorMiddleware
+func withErrorState(ctx context.Context, state *errorState) context.Context {
+	return context.WithValue(ctx, errorStateKey, state)
+}
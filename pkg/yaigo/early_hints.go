@@ -0,0 +1,58 @@
+package yaigo
+
+import (
+	"fmt"
+	"github.com/tortlewortle/yaigo/pkg/vite"
+	"net/http"
+	"slices"
+)
+
+// earlyHintLinks builds the Link header values preloading the scripts and stylesheets of the entries
+func earlyHintLinks(manifest *vite.Manifest, assetBaseURL string, entries []string) ([]string, error) {
+	var links []string
+	for _, entry := range entries {
+		scripts, styles, err := manifest.EntryFiles(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, script := range scripts {
+			links = append(links, fmt.Sprintf("<%s>; rel=modulepreload", assetURL(assetBaseURL, script)))
+		}
+		for _, style := range styles {
+			links = append(links, fmt.Sprintf("<%s>; rel=preload; as=style", assetURL(assetBaseURL, style)))
+		}
+	}
+	return links, nil
+}
+
+// sendEarlyHints adds the preload Link headers, which stay on the final response, and sends them in a 103 Early Hints
+// response when enabled with WithEarlyHintsResponse.
+// Nothing is sent again when the headers are already present, e.g. when rendering the error page after a failed render.
+func sendEarlyHints(w http.ResponseWriter, links []string, informational bool) {
+	h := w.Header()
+	existing := h.Values("Link")
+	var added bool
+	for _, link := range links {
+		if !slices.Contains(existing, link) {
+			h.Add("Link", link)
+			added = true
+		}
+	}
+	if added && informational {
+		writeEarlyHints(w)
+	}
+}
+
+// writeEarlyHints sends a 103 with the current headers to the innermost writer, unwrapping the same way as
+// http.ResponseController does. Wrapping writers often treat the first WriteHeader as the final status, the server's
+// own writers send informational responses without committing the response.
+func writeEarlyHints(w http.ResponseWriter) {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	w.WriteHeader(http.StatusEarlyHints)
+}
@@ -0,0 +1,160 @@
+package yaigo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"slices"
+	"testing"
+)
+
+// statusWriter is a typical logging middleware writer, it treats the first WriteHeader as the final status
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// getWithEarlyHints requests the url, returning the response and the links of the 103 if one was sent
+func getWithEarlyHints(t *testing.T, client *http.Client, url string) (*http.Response, []string) {
+	t.Helper()
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = header.Values("Link")
+			}
+			return nil
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), "GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	return resp, hints
+}
+
+func TestPage_RenderEarlyHints(t *testing.T) {
+	expected := []string{
+		"</static/assets/main-abc123.js>; rel=modulepreload",
+		"</static/assets/main-def456.css>; rel=preload; as=style",
+	}
+
+	for _, http2 := range []bool{false, true} {
+		config := newTestConfig(t, WithEarlyHints("src/main.ts"), WithEarlyHintsResponse(true), WithAssetBaseURL("/static"))
+		var logged *statusWriter
+		handler := Middleware(config)(ErrorMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = NewPage("Welcome", nil).Render(r.Context(), w)
+		})))
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logged = &statusWriter{ResponseWriter: w}
+			handler.ServeHTTP(logged, r)
+		}))
+		srv.EnableHTTP2 = http2
+		srv.StartTLS()
+
+		resp, hints := getWithEarlyHints(t, srv.Client(), srv.URL)
+		srv.Close()
+
+		if http2 && resp.ProtoMajor != 2 {
+			t.Fatalf("expected an http/2 response, got: %s", resp.Proto)
+		}
+		if resp.StatusCode != http.StatusOK || logged.status != http.StatusOK {
+			t.Errorf("http2=%v: unexpected final status: %d, logged %d", http2, resp.StatusCode, logged.status)
+		}
+		if !slices.Equal(hints, expected) {
+			t.Errorf("http2=%v: unexpected early hints: %v", http2, hints)
+		}
+		if !slices.Equal(resp.Header.Values("Link"), expected) {
+			t.Errorf("http2=%v: link headers should be kept on the final response: %v", http2, resp.Header.Values("Link"))
+		}
+	}
+
+	// inertia visits already have the assets loaded
+	config := newTestConfig(t, WithEarlyHints("src/main.ts"), WithEarlyHintsResponse(true))
+	rec := serveTestPage(config, NewPage("Welcome", nil), inertiaRequest(config, "/"))
+	if len(rec.Header().Values("Link")) != 0 {
+		t.Error("inertia requests should not get early hints")
+	}
+}
+
+func TestPage_RenderEarlyHintsDisabled(t *testing.T) {
+	config := newTestConfig(t, WithEarlyHints("src/main.ts"))
+	srv := httptest.NewServer(Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = NewPage("Welcome", nil).Render(r.Context(), w)
+	})))
+	defer srv.Close()
+
+	resp, hints := getWithEarlyHints(t, srv.Client(), srv.URL)
+	if hints != nil {
+		t.Errorf("expected no 103 without WithEarlyHintsResponse, got: %v", hints)
+	}
+	if len(resp.Header.Values("Link")) != 2 {
+		t.Errorf("expected the link headers on the final response: %v", resp.Header.Values("Link"))
+	}
+}
+
+func TestPage_RenderEarlyHintsRecorder(t *testing.T) {
+	config := newTestConfig(t, WithEarlyHints("src/main.ts"))
+
+	// a recorder stores the first status as the final one, it must not get a 103 by default
+	rec := serveTestPage(config, NewPage("Missing", nil).Status(http.StatusNotFound), httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+	if len(rec.Header().Values("Link")) != 2 {
+		t.Errorf("expected the link headers on the final response: %v", rec.Header().Values("Link"))
+	}
+}
+
+// earlyHintsRecorder counts the 103s instead of recording them as the final status
+type earlyHintsRecorder struct {
+	*httptest.ResponseRecorder
+	hints int
+}
+
+func (w *earlyHintsRecorder) WriteHeader(code int) {
+	if code == http.StatusEarlyHints {
+		w.hints++
+		return
+	}
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func TestPage_RenderEarlyHintsOnce(t *testing.T) {
+	config := newTestConfig(t, WithEarlyHints("src/main.ts"), WithEarlyHintsResponse(true))
+
+	w := &earlyHintsRecorder{ResponseRecorder: httptest.NewRecorder()}
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a failed render followed by the error page
+		_ = NewPage("Welcome", Props{"broken": func() {}}).Render(r.Context(), w)
+		_ = NewPage("Error", nil).Status(http.StatusInternalServerError).Render(r.Context(), w)
+	})).ServeHTTP(&statusWriter{ResponseWriter: w}, httptest.NewRequest("GET", "/", nil))
+
+	if w.hints != 1 {
+		t.Errorf("expected a single 103, got %d", w.hints)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if len(w.Header().Values("Link")) != 2 {
+		t.Errorf("link headers should not be duplicated: %v", w.Header().Values("Link"))
+	}
+}
@@ -0,0 +1,149 @@
+package yaigo
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+)
+
+// errorState tracks if the current request started writing an inertia page, these are never replaced by the error page
+type errorState struct {
+	rendering bool
+}
+
+// errorResponseWriter holds back the body of error responses so the error page can be rendered instead
+type errorResponseWriter struct {
+	http.ResponseWriter
+	state       *errorState
+	statuses    []int
+	status      int
+	wroteHeader bool
+}
+
+func (w *errorResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	if code < http.StatusOK {
+		// informational responses like early hints
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+	if !w.state.rendering && slices.Contains(w.statuses, code) {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *errorResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status != 0 {
+		// discard the original error body
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorResponseWriter) Flush() {
+	if w.status != 0 {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *errorResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ErrorMiddleware recovers panics and replaces error responses (404, 500, etc.) with the error component
+// configured with WithErrorPage, rendered with "status" and "message" props through the normal Page.Render pipeline.
+// Pages rendered by the handler itself are never replaced. Has to be used within the yaigo middleware.
+func ErrorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		config, ok := r.Context().Value(configKey).(*Config)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		state := &errorState{}
+		ew := &errorResponseWriter{
+			ResponseWriter: w,
+			state:          state,
+			statuses:       config.errorStatuses,
+		}
+		r = r.WithContext(withErrorState(r.Context(), state))
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			stack := debug.Stack()
+			config.logger.Error("recovered panic", slog.String("url", r.RequestURI), slog.Any("panic", rec), slog.String("stack", string(stack)))
+
+			if ew.wroteHeader && ew.status == 0 {
+				// the response has already started, nothing left to do
+				return
+			}
+			config.renderErrorPage(w, r, http.StatusInternalServerError, fmt.Errorf("panic: %v", rec), stack)
+		}()
+
+		next.ServeHTTP(ew, r)
+
+		if ew.status != 0 {
+			config.renderErrorPage(w, r, ew.status, nil, nil)
+		}
+	})
+}
+
+// RenderErrorPage renders the error component configured with WithErrorPage with the status,
+// the cause is only exposed to the client in debug mode. Has to be used within the yaigo middleware.
+func RenderErrorPage(w http.ResponseWriter, r *http.Request, status int, cause error) {
+	config, ok := r.Context().Value(configKey).(*Config)
+	if !ok {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	config.renderErrorPage(w, r, status, cause, nil)
+}
+
+// renderErrorPage renders the error component, the cause and stack are only included in debug mode
+func (s *Config) renderErrorPage(w http.ResponseWriter, r *http.Request, status int, cause error, stack []byte) {
+	props := Props{
+		"status":  status,
+		"message": http.StatusText(status),
+	}
+	if s.errorDebug {
+		if cause != nil {
+			props["error"] = cause.Error()
+		}
+		if stack != nil {
+			props["stack"] = string(stack)
+		}
+	}
+
+	p := NewPage(s.errorComponent, props).Status(status)
+
+	// the headers of the original response do not apply to the error page
+	w.Header().Del("Content-Length")
+	w.Header().Del("Content-Encoding")
+
+	err := p.Render(r.Context(), w)
+	if err != nil {
+		s.logger.Error("rendering error page", slog.Int("status", status), slog.Any("error", err))
+		http.Error(w, http.StatusText(status), status)
+	}
+}
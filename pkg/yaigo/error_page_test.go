@@ -0,0 +1,130 @@
+package yaigo
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveWithErrorMiddleware(config *Config, handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	Middleware(config)(ErrorMiddleware(handler)).ServeHTTP(rec, r)
+	return rec
+}
+
+func inertiaRequest(config *Config, path string) *http.Request {
+	r := httptest.NewRequest("GET", path, nil)
+	r.Header.Set(HeaderInertia, "true")
+	r.Header.Set(HeaderVersion, config.manifestVersion)
+	return r
+}
+
+func TestErrorMiddleware_Panic(t *testing.T) {
+	config := newTestConfig(t, WithErrorPage("Errors/Show", true))
+
+	rec := serveWithErrorMiddleware(config, func(w http.ResponseWriter, r *http.Request) {
+		NewPage("Welcome", Props{"broken": func() {}}).MustRender(r.Context(), w)
+	}, inertiaRequest(config, "/"))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	var data InertiaPage
+	err := json.Unmarshal(rec.Body.Bytes(), &data)
+	if err != nil {
+		t.Fatalf("expected an inertia response: %v: %s", err, rec.Body.String())
+	}
+	if data.Component != "Errors/Show" {
+		t.Errorf("unexpected component: %s", data.Component)
+	}
+	if data.Props["status"] != float64(500) {
+		t.Errorf("unexpected status prop: %v", data.Props["status"])
+	}
+	if stack, _ := data.Props["stack"].(string); !strings.Contains(stack, "error_page_test.go") {
+		t.Errorf("debug mode should include the stack trace: %v", data.Props["stack"])
+	}
+}
+
+func TestErrorMiddleware_FailedRender(t *testing.T) {
+	config := newTestConfig(t, WithErrorPage("Errors/Show", false), WithLayout("broken", func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`{{ .InertiaRoot }}{{ template "missing" }}`)
+	}))
+
+	for name, tt := range map[string]struct {
+		page      *Page
+		r         *http.Request
+		component func(t *testing.T, rec *httptest.ResponseRecorder) any
+	}{
+		"props": {NewPage("Welcome", Props{"broken": func() {}}), inertiaRequest(config, "/"), func(t *testing.T, rec *httptest.ResponseRecorder) any {
+			var data InertiaPage
+			err := json.Unmarshal(rec.Body.Bytes(), &data)
+			if err != nil {
+				t.Fatalf("expected the error page instead of the plain error: %v: %s", err, rec.Body.String())
+			}
+			return data.Component
+		}},
+		"template": {NewPage("Welcome", nil).Layout("broken"), httptest.NewRequest("GET", "/", nil), func(t *testing.T, rec *httptest.ResponseRecorder) any {
+			return decodeDataPage(t, rec.Body.String())["component"]
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serveWithErrorMiddleware(config, func(w http.ResponseWriter, r *http.Request) {
+				err := tt.page.Render(r.Context(), w)
+				if err == nil {
+					t.Fatal("expected the render to fail")
+				}
+				http.Error(w, "render failed", http.StatusInternalServerError)
+			}, tt.r)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("expected status 500, got %d", rec.Code)
+			}
+			if component := tt.component(t, rec); component != "Errors/Show" {
+				t.Errorf("unexpected component: %v", component)
+			}
+		})
+	}
+}
+
+func TestErrorMiddleware_NotFound(t *testing.T) {
+	config := newTestConfig(t)
+
+	rec := serveWithErrorMiddleware(config, http.NotFound, httptest.NewRequest("GET", "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "404 page not found") {
+		t.Error("the original error body should be replaced")
+	}
+	data := decodeDataPage(t, body)
+	if data["component"] != "Error" {
+		t.Errorf("unexpected component: %v", data["component"])
+	}
+	props := data["props"].(map[string]any)
+	if _, ok := props["stack"]; ok {
+		t.Error("stack should only be included in debug mode")
+	}
+}
+
+func TestErrorMiddleware_Passthrough(t *testing.T) {
+	config := newTestConfig(t)
+
+	rec := serveWithErrorMiddleware(config, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("teapot"))
+	}, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusTeapot || rec.Body.String() != "teapot" {
+		t.Errorf("unhandled statuses should pass through, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveWithErrorMiddleware(config, func(w http.ResponseWriter, r *http.Request) {
+		_ = NewPage("Users/Missing", nil).Status(http.StatusNotFound).Render(r.Context(), w)
+	}, inertiaRequest(config, "/"))
+	if !strings.Contains(rec.Body.String(), `"component":"Users/Missing"`) {
+		t.Errorf("rendered pages should not be replaced: %s", rec.Body.String())
+	}
+}
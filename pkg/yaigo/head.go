@@ -0,0 +1,164 @@
+package yaigo
+
+import (
+	"context"
+	"golang.org/x/net/html"
+	"html/template"
+	"slices"
+	"strings"
+)
+
+// HeadTags collects the title, meta and link tags rendered into the root template head
+//
+// Tags are deduplicated by their title/name/property/rel, the last one set wins.
+type HeadTags struct {
+	tags []headTag
+}
+
+type headTag struct {
+	key  string
+	html string
+}
+
+// Head returns the HeadTags for the current request
+func Head(ctx context.Context) *HeadTags {
+	head := headFromContext(ctx)
+	if head == nil {
+		panic("yaigo.Head: could not find head in ctx")
+	}
+	return head
+}
+
+func headFromContext(ctx context.Context) *HeadTags {
+	head, _ := ctx.Value(headKey).(*HeadTags)
+	return head
+}
+
+// Title sets the <title> of the page
+func (h *HeadTags) Title(title string) *HeadTags {
+	return h.add("<title>" + html.EscapeString(title) + "</title>")
+}
+
+// Description sets the description meta tag
+func (h *HeadTags) Description(description string) *HeadTags {
+	return h.Meta("description", description)
+}
+
+// Meta sets a <meta name="..." content="..."> tag
+func (h *HeadTags) Meta(name, content string) *HeadTags {
+	return h.add(`<meta name="` + html.EscapeString(name) + `" content="` + html.EscapeString(content) + `">`)
+}
+
+// Property sets a <meta property="..." content="..."> tag, used by OpenGraph
+func (h *HeadTags) Property(property, content string) *HeadTags {
+	return h.add(`<meta property="` + html.EscapeString(property) + `" content="` + html.EscapeString(content) + `">`)
+}
+
+// Canonical sets the canonical url of the page
+func (h *HeadTags) Canonical(href string) *HeadTags {
+	return h.Link("canonical", href)
+}
+
+// Link adds a <link rel="..." href="..."> tag
+func (h *HeadTags) Link(rel, href string) *HeadTags {
+	return h.add(`<link rel="` + html.EscapeString(rel) + `" href="` + html.EscapeString(href) + `">`)
+}
+
+func (h *HeadTags) add(tag string) *HeadTags {
+	t := headTag{
+		key:  headTagKey(tag),
+		html: tag,
+	}
+	i := slices.IndexFunc(h.tags, func(existing headTag) bool {
+		return t.key != "" && existing.key == t.key
+	})
+	if i >= 0 {
+		h.tags[i] = t
+	} else {
+		h.tags = append(h.tags, t)
+	}
+	return h
+}
+
+func (h *HeadTags) reset() {
+	h.tags = h.tags[:0]
+}
+
+// render renders the tags, skipping any that are already present in ssrHead.
+//
+// SSR tags take precedence since they match what the client renders after hydrating.
+func (h *HeadTags) render(ssrHead []string) string {
+	if h == nil || len(h.tags) == 0 {
+		return ""
+	}
+
+	ssrKeys := make([]string, 0, len(ssrHead))
+	for _, tag := range ssrHead {
+		ssrKeys = append(ssrKeys, headTagKey(tag))
+	}
+
+	var b strings.Builder
+	for _, t := range h.tags {
+		if t.key != "" && slices.Contains(ssrKeys, t.key) {
+			continue
+		}
+		b.WriteString(t.html)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// headTagKey identifies what a head tag describes so duplicates can be detected, returns "" for unique tags
+func headTagKey(tag string) string {
+	z := html.NewTokenizer(strings.NewReader(tag))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return ""
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		t := z.Token()
+		attr := func(name string) string {
+			for _, a := range t.Attr {
+				if a.Key == name {
+					return a.Val
+				}
+			}
+			return ""
+		}
+
+		switch t.Data {
+		case "title":
+			return "title"
+		case "meta":
+			if name := attr("name"); name != "" {
+				return "meta:name:" + name
+			}
+			if property := attr("property"); property != "" {
+				return "meta:property:" + property
+			}
+		case "link":
+			if rel := attr("rel"); rel == "canonical" {
+				return "link:canonical"
+			} else if rel != "" {
+				return "link:" + rel + ":" + attr("href")
+			}
+		}
+		return ""
+	}
+}
+
+// inertiaHead merges the base head, the tags set through Head and the ssr head
+func (p *Page) inertiaHead(ctx context.Context, config *Config, ssrHead []string) template.HTML {
+	head := string(p.inertiaBaseHead(ctx, config))
+	if tags := headFromContext(ctx).render(ssrHead); tags != "" {
+		head += "\n" + tags
+	}
+	if len(ssrHead) > 0 {
+		head += "\n" + strings.Join(ssrHead, "\n")
+	}
+	return template.HTML(head)
+}
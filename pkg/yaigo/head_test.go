@@ -0,0 +1,43 @@
+package yaigo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHead(t *testing.T) {
+	config := newTestConfig(t, WithSSRRenderer(&stubSSRRenderer{
+		head: []string{`<title inertia>SSR title</title>`},
+		body: `<div id="app"></div>`,
+	}))
+
+	rec := httptest.NewRecorder()
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Head(r.Context()).
+			Title("Go title").
+			Description("first").
+			Description("<second>").
+			Property("og:title", "Go & friends").
+			Canonical("https://example.com/")
+		_ = NewPage("Welcome", nil).Render(r.Context(), w)
+	})).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	body := rec.Body.String()
+
+	if strings.Contains(body, "Go title") {
+		t.Error("ssr title should take precedence over the go title")
+	}
+	if !strings.Contains(body, "<title inertia>SSR title</title>") {
+		t.Error("ssr title missing")
+	}
+	if strings.Count(body, `name="description"`) != 1 || !strings.Contains(body, `<meta name="description" content="&lt;second&gt;">`) {
+		t.Errorf("description should be deduplicated and escaped: %s", body)
+	}
+	if !strings.Contains(body, `<meta property="og:title" content="Go &amp; friends">`) {
+		t.Errorf("og:title missing: %s", body)
+	}
+	if !strings.Contains(body, `<link rel="canonical" href="https://example.com/">`) {
+		t.Errorf("canonical missing: %s", body)
+	}
+}
@@ -0,0 +1,24 @@
+package yaigo
+
+import (
+	"context"
+	"github.com/tortlewortle/yaigo/internal/page"
+)
+
+// EncryptHistory overrides the history encryption set by the middleware for the current request
+func EncryptHistory(ctx context.Context, encrypt bool) {
+	pageData, ok := ctx.Value(pageDataKey).(*page.InertiaPage)
+	if !ok {
+		panic("yaigo.EncryptHistory: could not find page data in ctx")
+	}
+	pageData.EncryptHistory = encrypt
+}
+
+// ClearHistory clears the client side history when rendering the page for the current request
+func ClearHistory(ctx context.Context) {
+	pageData, ok := ctx.Value(pageDataKey).(*page.InertiaPage)
+	if !ok {
+		panic("yaigo.ClearHistory: could not find page data in ctx")
+	}
+	pageData.ClearHistory = true
+}
@@ -0,0 +1,126 @@
+package yaigo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tortlewortle/yaigo/pkg/prop"
+)
+
+// RenderMode is the way a page is rendered
+type RenderMode string
+
+const (
+	RenderJSON RenderMode = "json"
+	RenderHTML RenderMode = "html"
+	RenderSSR  RenderMode = "ssr"
+)
+
+// Hooks are notified about the work done for a request, e.g. to create tracing spans or record metrics.
+//
+// Every Start method returns the context used for the work it wraps and a func that is called once it is done.
+// Embed NoopHooks to only implement some of them.
+type Hooks interface {
+	// StartMiddleware wraps the handler within the yaigo middleware
+	StartMiddleware(ctx context.Context, r *http.Request) (context.Context, func())
+	// StartProp wraps the resolution of every lazy prop
+	StartProp(ctx context.Context, info prop.Info) (context.Context, func(err error))
+	// StartRender wraps writing the page after the props are resolved
+	StartRender(ctx context.Context, component string, mode RenderMode) (context.Context, func(err error))
+	// SSRFallback is called when the ssr renderer is unavailable and the page is rendered client side instead
+	SSRFallback(ctx context.Context, component string, err error)
+	// StartTypeGen wraps generating the types of a page
+	StartTypeGen(ctx context.Context, component string) (context.Context, func(err error))
+}
+
+// NoopHooks does nothing, it is the default Hooks
+type NoopHooks struct{}
+
+func (NoopHooks) StartMiddleware(ctx context.Context, _ *http.Request) (context.Context, func()) {
+	return ctx, func() {}
+}
+
+func (NoopHooks) StartProp(ctx context.Context, _ prop.Info) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
+func (NoopHooks) StartRender(ctx context.Context, _ string, _ RenderMode) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
+func (NoopHooks) SSRFallback(context.Context, string, error) {}
+
+func (NoopHooks) StartTypeGen(ctx context.Context, _ string) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
+// Span is a unit of work recorded by the Recorder
+type Span struct {
+	// Op is one of "middleware", "prop", "render", "ssr_fallback" or "typegen"
+	Op string
+	// Name is the request path, prop name or component
+	Name string
+	// Mode is set for render spans
+	Mode RenderMode
+	// Prop is set for prop spans
+	Prop     prop.Info
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// Recorder keeps the spans of all Hooks calls in memory, meant for tests
+type Recorder struct {
+	lock  sync.Mutex
+	spans []Span
+}
+
+// Spans returns the finished spans in the order they ended
+func (r *Recorder) Spans() []Span {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Span(nil), r.spans...)
+}
+
+// Reset removes all recorded spans
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = nil
+}
+
+func (r *Recorder) start(span Span) func(err error) {
+	span.Start = time.Now()
+	return func(err error) {
+		span.Duration = time.Since(span.Start)
+		span.Err = err
+		r.lock.Lock()
+		r.spans = append(r.spans, span)
+		r.lock.Unlock()
+	}
+}
+
+func (r *Recorder) StartMiddleware(ctx context.Context, req *http.Request) (context.Context, func()) {
+	end := r.start(Span{Op: "middleware", Name: req.URL.Path})
+	return ctx, func() {
+		end(nil)
+	}
+}
+
+func (r *Recorder) StartProp(ctx context.Context, info prop.Info) (context.Context, func(err error)) {
+	return ctx, r.start(Span{Op: "prop", Name: info.Name, Prop: info})
+}
+
+func (r *Recorder) StartRender(ctx context.Context, component string, mode RenderMode) (context.Context, func(err error)) {
+	return ctx, r.start(Span{Op: "render", Name: component, Mode: mode})
+}
+
+func (r *Recorder) SSRFallback(_ context.Context, component string, err error) {
+	r.start(Span{Op: "ssr_fallback", Name: component})(err)
+}
+
+func (r *Recorder) StartTypeGen(ctx context.Context, component string) (context.Context, func(err error)) {
+	return ctx, r.start(Span{Op: "typegen", Name: component})
+}
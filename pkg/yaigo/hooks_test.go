@@ -0,0 +1,115 @@
+package yaigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tortlewortle/yaigo/pkg/prop"
+)
+
+func spansByOp(spans []Span, op string) []Span {
+	var out []Span
+	for _, s := range spans {
+		if s.Op == op {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestHooks_Recorder(t *testing.T) {
+	rec := &Recorder{}
+	config := newTestConfig(t, WithHooks(rec))
+
+	p := NewPage("Dashboard", Props{
+		"title": "dashboard",
+		"stats": prop.GoAny(func(ctx context.Context) (any, error) {
+			return 42, nil
+		}),
+		"feed": prop.DeferAny(func(ctx context.Context) (any, error) {
+			return nil, nil
+		}).Group("feed"),
+	})
+	serveTestPage(config, p, inertiaRequest(config, "/dashboard"))
+
+	spans := rec.Spans()
+	if m := spansByOp(spans, "middleware"); len(m) != 1 || m[0].Name != "/dashboard" {
+		t.Errorf("expected a middleware span, got: %+v", m)
+	}
+	if r := spansByOp(spans, "render"); len(r) != 1 || r[0].Mode != RenderJSON || r[0].Name != "Dashboard" {
+		t.Errorf("expected a json render span, got: %+v", r)
+	}
+	props := spansByOp(spans, "prop")
+	if len(props) != 1 || props[0].Name != "stats" || props[0].Prop.Sync || props[0].Prop.Deferred {
+		t.Errorf("expected only the async stats prop, got: %+v", props)
+	}
+	// the middleware finishes last
+	if spans[len(spans)-1].Op != "middleware" {
+		t.Errorf("expected the middleware span last, got: %+v", spans)
+	}
+
+	rec.Reset()
+	r := httptest.NewRequest("GET", "/dashboard", nil)
+	r.Header.Set(HeaderInertia, "true")
+	r.Header.Set(HeaderVersion, config.manifestVersion)
+	r.Header.Set(HeaderPartialComponent, "Dashboard")
+	r.Header.Set(HeaderPartialOnly, "feed")
+	serveTestPage(config, p, r)
+
+	props = spansByOp(rec.Spans(), "prop")
+	if len(props) != 1 || props[0].Name != "feed" || !props[0].Prop.Deferred || props[0].Prop.Group != "feed" {
+		t.Errorf("expected the deferred feed prop, got: %+v", props)
+	}
+}
+
+func TestHooks_MiddlewareSpan(t *testing.T) {
+	rec := &Recorder{}
+	config := newTestConfig(t, WithHooks(rec))
+
+	r := httptest.NewRequest("GET", "/stale", nil)
+	r.Header.Set(HeaderInertia, "true")
+	r.Header.Set(HeaderVersion, "stale")
+	w := httptest.NewRecorder()
+	Middleware(config)(http.NotFoundHandler()).ServeHTTP(w, r)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected a version conflict, got: %d", w.Code)
+	}
+	if m := spansByOp(rec.Spans(), "middleware"); len(m) != 1 || m[0].Name != "/stale" {
+		t.Errorf("expected a middleware span for the version conflict, got: %+v", m)
+	}
+
+	rec.Reset()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the handler panic to propagate")
+			}
+		}()
+		Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	}()
+	if m := spansByOp(rec.Spans(), "middleware"); len(m) != 1 || m[0].Name != "/panic" {
+		t.Errorf("expected the middleware span to end on a panic, got: %+v", m)
+	}
+}
+
+func TestHooks_SSRFallback(t *testing.T) {
+	rec := &Recorder{}
+	config := newTestConfig(t, WithHooks(rec), WithSSRRenderer(&stubSSRRenderer{
+		err: errors.Join(ErrSSRUnavailable, errors.New("connection refused")),
+	}))
+
+	serveTestPage(config, NewPage("Welcome", nil), httptest.NewRequest("GET", "/", nil))
+
+	spans := rec.Spans()
+	if f := spansByOp(spans, "ssr_fallback"); len(f) != 1 || !errors.Is(f[0].Err, ErrSSRUnavailable) {
+		t.Errorf("expected an ssr fallback, got: %+v", f)
+	}
+	if r := spansByOp(spans, "render"); len(r) != 1 || r[0].Mode != RenderSSR || r[0].Err != nil {
+		t.Errorf("expected a successful ssr render span, got: %+v", r)
+	}
+}
@@ -0,0 +1,83 @@
+package yaigo
+
+import (
+	"context"
+	"fmt"
+	"github.com/tortlewortle/yaigo/pkg/vite"
+	"html/template"
+	"strings"
+)
+
+// layout is a root template together with the entries it preloads
+type layout struct {
+	template   *template.Template
+	earlyHints []string
+}
+
+type layoutOpts struct {
+	tfn     func(*template.Template) (*template.Template, error)
+	entries []string
+}
+
+type layoutPrefix struct {
+	prefix string
+	name   string
+}
+
+func newLayout(tfn func(*template.Template) (*template.Template, error), entries []string, manifest *vite.Manifest, opts *ServerOpts) (*layout, error) {
+	tmpl, err := generateRootTemplate(tfn, manifest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var earlyHints []string
+	// the vite dev server serves the assets itself
+	if len(entries) > 0 && opts.ViteUrl == "" {
+		earlyHints, err = earlyHintLinks(manifest, opts.AssetBaseURL, entries)
+		if err != nil {
+			return nil, fmt.Errorf("early hints: %w", err)
+		}
+	}
+
+	return &layout{
+		template:   tmpl,
+		earlyHints: earlyHints,
+	}, nil
+}
+
+// layoutForPath returns the layout name registered for the longest matching prefix of the path
+func (s *Config) layoutForPath(path string) string {
+	var match layoutPrefix
+	for _, lp := range s.layoutPrefixes {
+		if strings.HasPrefix(path, lp.prefix) && len(lp.prefix) > len(match.prefix) {
+			match = lp
+		}
+	}
+	return match.name
+}
+
+// getLayout returns the named layout, the default root template is returned for an empty name
+func (s *Config) getLayout(name string) (*layout, error) {
+	if name == "" {
+		return s.rootLayout, nil
+	}
+	l, ok := s.layouts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown layout %q", name)
+	}
+	return l, nil
+}
+
+// SetLayout selects the named layout for the current request, Page.Layout takes precedence
+func SetLayout(ctx context.Context, name string) {
+	req, ok := ctx.Value(layoutKey).(*layoutSelection)
+	if !ok {
+		panic("yaigo.SetLayout: could not find layout in ctx")
+	}
+	req.name = name
+}
+
+// layoutSelection holds the layout selected for a request through the prefix or SetLayout
+type layoutSelection struct {
+	name string
+}
@@ -0,0 +1,66 @@
+package yaigo
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func adminTemplate(t *template.Template) (*template.Template, error) {
+	return t.Parse(`<html class="admin"><head></head><body>{{ .InertiaRoot }}</body></html>`)
+}
+
+func TestPage_Layout(t *testing.T) {
+	config := newTestConfig(t,
+		WithLayout("admin", adminTemplate),
+		WithLayoutPrefix("/admin/", "admin"),
+	)
+
+	tests := []struct {
+		name    string
+		path    string
+		page    *Page
+		setup   func(r *http.Request)
+		isAdmin bool
+	}{
+		{"default", "/", NewPage("Welcome", nil), nil, false},
+		{"page layout", "/", NewPage("Welcome", nil).Layout("admin"), nil, true},
+		{"prefix", "/admin/users", NewPage("Users", nil), nil, true},
+		{"set layout", "/", NewPage("Welcome", nil), func(r *http.Request) { SetLayout(r.Context(), "admin") }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.setup != nil {
+					tt.setup(r)
+				}
+				_ = tt.page.Render(r.Context(), w)
+			})).ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+			if isAdmin := strings.Contains(rec.Body.String(), `class="admin"`); isAdmin != tt.isAdmin {
+				t.Errorf("expected admin layout %v, got: %s", tt.isAdmin, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPage_LayoutUnknown(t *testing.T) {
+	config := newTestConfig(t)
+
+	var err error
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = NewPage("Welcome", nil).Layout("missing").Render(r.Context(), w)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if err == nil {
+		t.Error("expected an error for an unknown layout")
+	}
+
+	_, err = New(testTemplate, testFrontend, WithLayoutPrefix("/admin", "missing"))
+	if err == nil {
+		t.Error("expected an error for a prefix with an unknown layout")
+	}
+}
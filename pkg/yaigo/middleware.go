@@ -39,8 +39,22 @@ func Middleware(config *Config, opts ...func(*MiddlewareOpts)) func(http.Handler
 			return page.New()
 		},
 	}
+	headPool := sync.Pool{
+		New: func() interface{} {
+			return &HeadTags{}
+		},
+	}
+	templateDataPool := sync.Pool{
+		New: func() interface{} {
+			return make(map[string]any)
+		},
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the span also covers the version conflict and a panicking handler
+			spanCtx, end := config.hooks.StartMiddleware(r.Context(), r)
+			defer end()
+
 			info := infoPool.Get().(*RequestInfo)
 			info.Fill(r)
 
@@ -52,8 +66,10 @@ func Middleware(config *Config, opts ...func(*MiddlewareOpts)) func(http.Handler
 				infoPool.Put(info)
 				return
 			}
-			bag := bagPool.Get().(*prop.Bag)
+			bag := config.setupBag(bagPool.Get().(*prop.Bag))
 			pageData := inertiaPagePool.Get().(*page.InertiaPage)
+			head := headPool.Get().(*HeadTags)
+			tmplData := templateDataPool.Get().(map[string]any)
 
 			pageData.Version = config.manifestVersion
 			pageData.Url = r.RequestURI
@@ -62,12 +78,23 @@ func Middleware(config *Config, opts ...func(*MiddlewareOpts)) func(http.Handler
 			errs := errflash.GetErrors(w, r)
 			bag.Set("errors", errs)
 
-			ctx := WithConfig(r.Context(), config)
+			ctx := WithConfig(spanCtx, config)
 			ctx = WithRequestInfo(ctx, info)
 			ctx = WithPropBag(ctx, bag)
 			ctx = WithInertiaPage(ctx, pageData)
+			ctx = WithHead(ctx, head)
+			ctx = WithTemplateData(ctx, tmplData)
+			ctx = withLayoutSelection(ctx, &layoutSelection{name: config.layoutForPath(r.URL.Path)})
+			if config.nonceFunc != nil {
+				ctx = WithNonce(ctx, config.nonceFunc(r))
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 
+			// a prop fn abandoned after its timeout can still reach all of these through its context
+			if bag.Abandoned() {
+				return
+			}
+
 			// empty and return values to pool
 			info.Empty()
 			infoPool.Put(info)
@@ -77,6 +104,12 @@ func Middleware(config *Config, opts ...func(*MiddlewareOpts)) func(http.Handler
 
 			pageData.Reset()
 			inertiaPagePool.Put(pageData)
+
+			head.reset()
+			headPool.Put(head)
+
+			clear(tmplData)
+			templateDataPool.Put(tmplData)
 		})
 	}
 }
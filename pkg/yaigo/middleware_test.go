@@ -0,0 +1,51 @@
+package yaigo
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tortlewortle/yaigo/pkg/prop"
+)
+
+func TestMiddleware_AbandonedProp(t *testing.T) {
+	config, err := New(func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`{{ .InertiaRoot }}{{ .Data.leaked }}`)
+	}, testFrontend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	written := make(chan struct{})
+	slow := prop.GoAny(func(ctx context.Context) (any, error) {
+		defer close(written)
+		<-release
+		// the request is over, this must not end up in the next one
+		SetTemplateData(ctx, "leaked", "from the previous request")
+		return nil, nil
+	}).Timeout(10 * time.Millisecond)
+
+	// both requests have to go through the same middleware to share its pools
+	handler := Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		props := Props{}
+		if r.URL.Path == "/slow" {
+			props["slow"] = slow
+		}
+		_ = NewPage("Page", props).Render(r.Context(), w)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	close(release)
+	<-written
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if strings.Contains(rec.Body.String(), "from the previous request") {
+		t.Errorf("abandoned prop leaked into the next request: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,55 @@
+package yaigo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"golang.org/x/net/html"
+	"net/http"
+	"strings"
+)
+
+// NoncePlaceholder is replaced with the request nonce in the policy passed to CSPMiddleware
+const NoncePlaceholder = "{nonce}"
+
+// nonceAttrPlaceholder is emitted by the template funcs in place of the nonce attribute and replaced with the request
+// nonce while rendering. Head tags and ssr output are inserted without escaping NUL, so it is random per process to
+// keep page content from producing it.
+var nonceAttrPlaceholder = "\x00yaigo:nonce:" + rand.Text() + "\x00"
+
+// NonceFunc provides the Content-Security-Policy nonce for a request
+type NonceFunc = func(r *http.Request) string
+
+// GenerateNonce returns a random base64 encoded nonce, this is the default NonceFunc
+func GenerateNonce(_ *http.Request) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Nonce returns the Content-Security-Policy nonce for the current request, empty when nonces are disabled
+func Nonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(nonceKey).(string)
+	return nonce
+}
+
+// CSPMiddleware sets the Content-Security-Policy header, NoncePlaceholder in the policy is replaced with the request
+// nonce. Has to be used within the yaigo middleware.
+//
+// e.g. CSPMiddleware("script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'")
+func CSPMiddleware(policy string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Security-Policy", strings.ReplaceAll(policy, NoncePlaceholder, Nonce(r.Context())))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// nonceAttr returns the nonce attribute including a leading space, or nothing without a nonce
+func nonceAttr(nonce string) string {
+	if nonce == "" {
+		return ""
+	}
+	return ` nonce="` + html.EscapeString(nonce) + `"`
+}
@@ -0,0 +1,75 @@
+package yaigo
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSPNonce(t *testing.T) {
+	tmpl := func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`<html><head>{{ viteCSS "src/main.ts" }}{{ .InertiaHead }}{{ viteScript "src/main.ts" }}</head><body>{{ .InertiaRoot }}</body></html>`)
+	}
+	config, err := New(tmpl, testFrontend, WithCSPNonceFunc(func(_ *http.Request) string {
+		return "abc123"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.reactRefresh = true
+
+	for _, stream := range []bool{false, true} {
+		config.streamHTML = stream
+		rec := httptest.NewRecorder()
+		handler := CSPMiddleware("script-src 'nonce-{nonce}'")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// user content must not be able to get the nonce injected
+			Head(r.Context()).Title("\x00yaigo:nonce\x00")
+			_ = NewPage("Welcome", nil).Render(r.Context(), w)
+		}))
+		Middleware(config)(handler).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		body := rec.Body.String()
+
+		if csp := rec.Header().Get("Content-Security-Policy"); csp != "script-src 'nonce-abc123'" {
+			t.Errorf("stream=%v: unexpected csp header: %s", stream, csp)
+		}
+		if !strings.Contains(body, `<script type="module" nonce="abc123">`) {
+			t.Errorf("stream=%v: react refresh script is missing the nonce: %s", stream, body)
+		}
+		if !strings.Contains(body, `<link rel="stylesheet" href="/assets/main-def456.css" nonce="abc123"/>`) {
+			t.Errorf("stream=%v: stylesheet is missing the nonce: %s", stream, body)
+		}
+		if !strings.Contains(body, `<script type="module" src="/assets/main-abc123.js" nonce="abc123"></script>`) {
+			t.Errorf("stream=%v: script is missing the nonce: %s", stream, body)
+		}
+		if n := strings.Count(body, `nonce="abc123"`); n != 4 {
+			t.Errorf("stream=%v: expected the nonce on the 4 asset tags only, got %d: %q", stream, n, body)
+		}
+		if strings.Contains(body, nonceAttrPlaceholder) {
+			t.Errorf("stream=%v: nonce placeholder left in: %q", stream, body)
+		}
+	}
+}
+
+func TestCSPNonceDisabled(t *testing.T) {
+	tmpl := func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`<html><head>{{ viteScript "src/main.ts" }}</head><body>{{ .InertiaRoot }}</body></html>`)
+	}
+	config, err := New(tmpl, testFrontend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := serveTestPage(config, NewPage("Welcome", nil), httptest.NewRequest("GET", "/", nil)).Body.String()
+	if !strings.Contains(body, `<script type="module" src="/assets/main-abc123.js"></script>`) {
+		t.Errorf("expected a script without nonce: %q", body)
+	}
+}
+
+func TestGenerateNonce(t *testing.T) {
+	a, b := GenerateNonce(nil), GenerateNonce(nil)
+	if a == "" || a == b {
+		t.Errorf("expected unique nonces, got %q and %q", a, b)
+	}
+}
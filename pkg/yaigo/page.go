@@ -13,7 +13,6 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 )
@@ -29,6 +28,9 @@ type Page struct {
 	component    string
 	pageProps    Props
 	clearHistory bool
+	noSSRCache   bool
+	layout       string
+	status       int
 }
 
 func (p *Page) ClearHistory() *Page {
@@ -36,6 +38,24 @@ func (p *Page) ClearHistory() *Page {
 	return p
 }
 
+// Layout renders the page using a root template registered with WithLayout
+func (p *Page) Layout(name string) *Page {
+	p.layout = name
+	return p
+}
+
+// Status sets the http status code for both inertia and full page responses, defaults to 200
+func (p *Page) Status(code int) *Page {
+	p.status = code
+	return p
+}
+
+// NoSSRCache skips the ssr cache for this page, use this for pages that carry per-user data
+func (p *Page) NoSSRCache() *Page {
+	p.noSSRCache = true
+	return p
+}
+
 func (p *Page) MustRender(ctx context.Context, w io.Writer) {
 	err := p.Render(ctx, w)
 	if err != nil {
@@ -50,11 +70,28 @@ func (p *Page) Render(ctx context.Context, w io.Writer) error {
 	if bagVal := ctx.Value(bagKey); bagVal != nil {
 		bag = bagVal.(*prop.Bag)
 	} else {
-		bag = prop.NewBag()
+		bag = config.setupBag(prop.NewBag())
 	}
 	pageData := ctx.Value(pageDataKey).(*page.InertiaPage)
 	pageData.Component = p.component
-	pageData.ClearHistory = p.clearHistory
+	// ClearHistory may have been called for the whole request already
+	pageData.ClearHistory = pageData.ClearHistory || p.clearHistory
+
+	layoutName := p.layout
+	if sel, ok := ctx.Value(layoutKey).(*layoutSelection); ok && layoutName == "" {
+		layoutName = sel.name
+	}
+	root, err := config.getLayout(layoutName)
+	if err != nil {
+		return err
+	}
+
+	if len(root.earlyHints) > 0 && !requestInfo.IsInertia() {
+		if rw, ok := w.(http.ResponseWriter); ok {
+			// let the browser start fetching the entry assets while the props resolve
+			sendEarlyHints(rw, root.earlyHints, config.earlyHints103)
+		}
+	}
 
 	bag.Checkpoint()
 
@@ -75,7 +112,6 @@ func (p *Page) Render(ctx context.Context, w io.Writer) error {
 		}
 	}
 
-	var err error
 	pageData.Props, err = bag.GetProps(ctx)
 	if err != nil {
 		return fmt.Errorf("loading props: %w", err)
@@ -85,7 +121,9 @@ func (p *Page) Render(ctx context.Context, w io.Writer) error {
 	// todo: maybe move away?
 	if config.typeGenerator != nil {
 		start := time.Now()
+		_, end := config.hooks.StartTypeGen(ctx, p.component)
 		err := config.typeGenerator.Generate(pageData)
+		end(err)
 		if err != nil {
 			config.logger.Warn("typegen failed", slog.String("component", p.component), slog.Any("error", err))
 		}
@@ -93,102 +131,156 @@ func (p *Page) Render(ctx context.Context, w io.Writer) error {
 	}
 
 	if requestInfo.IsInertia() {
-		return p.renderJson(w, pageData)
+		ctx, end := config.hooks.StartRender(ctx, p.component, RenderJSON)
+		err = p.renderJson(ctx, w, pageData)
+		end(err)
+		return err
 	}
 
-	if config.ssrURL != "" {
-		err = p.renderSSR(config, w, pageData)
-		if err != nil {
-			if errors.Is(err, errCommunicatingWithSSRServer) {
-				// render client side if ssr is unreachable
-				return p.renderHtml(config, w, pageData)
-			}
-			return err
+	if config.ssrRenderer != nil {
+		ctx, end := config.hooks.StartRender(ctx, p.component, RenderSSR)
+		err = p.renderSSR(ctx, config, root, w, pageData)
+		if errors.Is(err, ErrSSRUnavailable) {
+			config.hooks.SSRFallback(ctx, p.component, err)
+			// render client side if ssr is unreachable
+			err = p.renderHtml(ctx, config, root, w, pageData)
 		}
-		return nil
+		end(err)
+		return err
 	}
-	return p.renderHtml(config, w, pageData)
+
+	ctx, end := config.hooks.StartRender(ctx, p.component, RenderHTML)
+	err = p.renderHtml(ctx, config, root, w, pageData)
+	end(err)
+	return err
 }
 
-func (p *Page) renderJson(w io.Writer, data *page.InertiaPage) error {
+// writeStatus writes the page status code, has to be called after setting the headers and any work that may fail.
+// From here on the response belongs to the page and is not replaced by the error page anymore.
+func (p *Page) writeStatus(ctx context.Context, rw http.ResponseWriter) {
+	if state, ok := ctx.Value(errorStateKey).(*errorState); ok {
+		state.rendering = true
+	}
+	if p.status != 0 {
+		rw.WriteHeader(p.status)
+	}
+}
+
+func (p *Page) renderJson(ctx context.Context, w io.Writer, data *page.InertiaPage) error {
+	propStr, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
 	if rw, ok := w.(http.ResponseWriter); ok {
 		rw.Header().Set(HeaderInertia, "true")
 		rw.Header().Set("Content-Type", "application/json")
 		rw.Header().Set("Vary", HeaderInertia)
+		p.writeStatus(ctx, rw)
 	}
-	err := json.NewEncoder(w).Encode(data)
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err = w.Write(append(propStr, '\n'))
+	return err
 }
 
-func (p *Page) renderHtml(config *Config, w io.Writer, data *page.InertiaPage) error {
-	if rw, ok := w.(http.ResponseWriter); ok {
-		rw.Header().Set("Content-Type", "text/html")
+func (p *Page) renderHtml(ctx context.Context, config *Config, root *layout, w io.Writer, data *page.InertiaPage) error {
+	if config.streamHTML {
+		return p.streamHtml(ctx, config, root, w, data)
 	}
 	propStr, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	inertiaRoot := template.HTML(fmt.Sprintf("<div id=\"app\" data-page='%s'></div>", html.EscapeString(string(propStr))))
-	return config.rootTemplate.Execute(w, rootTmplData{
-		InertiaRoot: inertiaRoot,
-		InertiaHead: p.inertiaBaseHead(config),
-	})
+	rootID := html.EscapeString(config.rootElementID)
+	var inertiaRoot template.HTML
+	if config.pageScriptTag {
+		// json.Marshal escapes <, > and & so the json can never close the script tag
+		inertiaRoot = template.HTML(fmt.Sprintf("<script data-page=\"%s\" type=\"application/json\">%s</script><div id=\"%s\"></div>", rootID, propStr, rootID))
+	} else {
+		inertiaRoot = template.HTML(fmt.Sprintf("<div id=\"%s\" data-page='%s'></div>", rootID, html.EscapeString(string(propStr))))
+	}
+	return p.writeTemplate(ctx, root, w, p.rootTemplateData(ctx, config, data, inertiaRoot, nil))
 }
 
-type ssrResponse struct {
-	Head []string `json:"head"`
-	Body string   `json:"body"`
+func (p *Page) renderSSR(ctx context.Context, config *Config, root *layout, w io.Writer, data *page.InertiaPage) error {
+	head, body, err := p.ssrRender(ctx, config, data)
+	if err != nil {
+		return err
+	}
+
+	return p.writeTemplate(ctx, root, w, p.rootTemplateData(ctx, config, data, template.HTML(body), head))
 }
 
-var errCommunicatingWithSSRServer = errors.New("could not communicate with ssr Config")
+// writeTemplate executes the root template into a buffer first, so a failing template does not leave a committed
+// status without a body behind
+func (p *Page) writeTemplate(ctx context.Context, root *layout, w io.Writer, data rootTmplData) error {
+	buf := templateBufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer templateBufPool.Put(buf)
 
-func (p *Page) renderSSR(config *Config, w io.Writer, data *page.InertiaPage) error {
-	renderPath, err := url.JoinPath(config.ssrURL, "/render")
+	err := root.template.Execute(buf, data)
 	if err != nil {
 		return err
 	}
-	pData, err := json.Marshal(data)
-	if err != nil {
-		return err
+	out := buf.Bytes()
+	if bytes.Contains(out, []byte(nonceAttrPlaceholder)) {
+		out = bytes.ReplaceAll(out, []byte(nonceAttrPlaceholder), []byte(nonceAttr(data.Nonce)))
 	}
 
-	ssrReq, err := http.NewRequest("GET", renderPath, bytes.NewReader(pData))
-	if err != nil {
-		return errors.Join(errCommunicatingWithSSRServer, err)
+	if rw, ok := w.(http.ResponseWriter); ok {
+		rw.Header().Set("Content-Type", "text/html")
+		p.writeStatus(ctx, rw)
 	}
+	_, err = w.Write(out)
+	return err
+}
 
-	resp, err := config.ssrHTTPClient.Do(ssrReq)
+// ssrRender renders the page using the ssr renderer, going through the ssr cache when enabled
+func (p *Page) ssrRender(ctx context.Context, config *Config, data *page.InertiaPage) ([]string, string, error) {
+	if config.ssrCache == nil || p.noSSRCache {
+		return config.ssrRenderer.Render(ctx, data)
+	}
+
+	key, err := ssrCacheKeyFor(data)
 	if err != nil {
-		return errors.Join(errCommunicatingWithSSRServer, err)
+		return nil, "", err
+	}
+	if head, body, ok := config.ssrCache.get(key); ok {
+		return head, body, nil
 	}
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(resp.Body)
 
-	var ssrRes ssrResponse
-	err = json.NewDecoder(resp.Body).Decode(&ssrRes)
+	head, body, err := config.ssrRenderer.Render(ctx, data)
 	if err != nil {
-		return errors.Join(errCommunicatingWithSSRServer, err)
+		return nil, "", err
 	}
+	config.ssrCache.set(key, head, body)
+	return head, body, nil
+}
 
-	if rw, ok := w.(http.ResponseWriter); ok {
-		rw.Header().Set("Content-Type", "text/html")
+func (p *Page) rootTemplateData(ctx context.Context, config *Config, data *page.InertiaPage, inertiaRoot template.HTML, ssrHead []string) rootTmplData {
+	var locale string
+	if requestInfo, ok := ctx.Value(requestInfoKey).(*RequestInfo); ok {
+		locale = requestInfo.Locale()
 	}
+	tmplData, _ := ctx.Value(templateDataKey).(map[string]any)
 
-	baseHead := p.inertiaBaseHead(config)
-	return config.rootTemplate.Execute(w, rootTmplData{
-		InertiaRoot: template.HTML(ssrRes.Body),
-		InertiaHead: baseHead + "\n" + template.HTML(strings.Join(ssrRes.Head, "\n")), // this is for SSR later
-	})
+	return rootTmplData{
+		InertiaRoot: inertiaRoot,
+		InertiaHead: p.inertiaHead(ctx, config, ssrHead),
+		Nonce:       Nonce(ctx),
+		Page:        data,
+		Locale:      locale,
+		Data:        tmplData,
+	}
 }
 
-func (p *Page) inertiaBaseHead(config *Config) template.HTML {
+func (p *Page) inertiaBaseHead(ctx context.Context, config *Config) template.HTML {
 	if config.reactRefresh {
-		return p.reactRefreshScript(config, nil)
+		var attrs []template.HTMLAttr
+		if nonce := Nonce(ctx); nonce != "" {
+			attrs = append(attrs, template.HTMLAttr(strings.TrimSpace(nonceAttr(nonce))))
+		}
+		return p.reactRefreshScript(config, attrs)
 	}
 	return ""
 }
@@ -198,12 +290,12 @@ func (p *Page) reactRefreshScript(config *Config, attrs []template.HTMLAttr) tem
 	if attrs != nil {
 		var attrBuilder strings.Builder
 		for _, a := range attrs {
-			attrBuilder.WriteString(string(a))
 			attrBuilder.WriteString(" ")
+			attrBuilder.WriteString(string(a))
 		}
 		attributes = attrBuilder.String()
 	}
-	return template.HTML(fmt.Sprintf(`<script type="module" %s>
+	return template.HTML(fmt.Sprintf(`<script type="module"%s>
 	import RefreshRuntime from '%s/@react-refresh'
 	RefreshRuntime.injectIntoGlobalHook(window)
 	window.$RefreshReg$ = () => {}
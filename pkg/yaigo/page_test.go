@@ -0,0 +1,298 @@
+package yaigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tortlewortle/yaigo/pkg/prop"
+)
+
+var dataPageAttr = regexp.MustCompile(`data-page='([^']*)'`)
+
+func decodeDataPage(t *testing.T, body string) map[string]any {
+	t.Helper()
+	match := dataPageAttr.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("data-page attribute not found in: %s", body)
+	}
+	var data map[string]any
+	err := json.Unmarshal([]byte(html.UnescapeString(match[1])), &data)
+	if err != nil {
+		t.Fatalf("decoding data-page: %v", err)
+	}
+	return data
+}
+
+func TestPage_StreamHtml(t *testing.T) {
+	props := Props{
+		"quote":   "it's <b>\"escaped\"</b> & more\r\n",
+		"numbers": []int{1, 2, 3},
+	}
+
+	buffered := serveTestPage(newTestConfig(t), NewPage("Welcome", props), httptest.NewRequest("GET", "/", nil))
+	streamed := serveTestPage(newTestConfig(t, WithHTMLStreaming(true)), NewPage("Welcome", props), httptest.NewRequest("GET", "/", nil))
+
+	if ct := streamed.Header().Get("Content-Type"); ct != "text/html" {
+		t.Errorf("unexpected content type: %s", ct)
+	}
+	if !streamed.Flushed {
+		t.Error("expected the template head to be flushed")
+	}
+
+	bufferedPage := decodeDataPage(t, buffered.Body.String())
+	streamedPage := decodeDataPage(t, streamed.Body.String())
+	if !reflect.DeepEqual(bufferedPage, streamedPage) {
+		t.Errorf("page mismatch:\nbuffered: %v\nstreamed: %v", bufferedPage, streamedPage)
+	}
+}
+
+func TestPage_StreamHtmlNoRoot(t *testing.T) {
+	config, err := New(func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`<html><body></body></html>`)
+	}, testFrontend, WithHTMLStreaming(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := NewPage("Welcome", nil).Render(r.Context(), w)
+		if !errors.Is(err, errNoInertiaRoot) {
+			t.Errorf("expected errNoInertiaRoot, got: %v", err)
+		}
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestPatternWriter_SplitPlaceholder(t *testing.T) {
+	var out bytes.Buffer
+	sw := &patternWriter{
+		w:       &out,
+		pattern: []byte(inertiaRootPlaceholder),
+		onMatch: func(w io.Writer) error {
+			_, err := io.WriteString(w, "ROOT")
+			return err
+		},
+	}
+
+	doc := "<body><!-- other -->" + inertiaRootPlaceholder + "</body>"
+	// write byte by byte so the placeholder is split over every possible boundary
+	for i := range len(doc) {
+		_, err := sw.Write([]byte{doc[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := sw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sw.matches != 1 || out.String() != "<body><!-- other -->ROOT</body>" {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
+
+func TestAttrEscapeWriter(t *testing.T) {
+	var out bytes.Buffer
+	_, err := attrEscapeWriter{&out}.Write([]byte("it's <b>\"escaped\"</b> & more\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "it&#39;s &lt;b&gt;&#34;escaped&#34;&lt;/b&gt; &amp; more&#13;\n"
+	if out.String() != expected {
+		t.Errorf("expected %s, got %s", expected, out.String())
+	}
+}
+
+var pageScriptTag = regexp.MustCompile(`(?s)<script data-page="root" type="application/json">(.*?)</script><div id="root"></div>`)
+
+func TestPage_RenderPageScriptTag(t *testing.T) {
+	props := Props{"evil": "</script><script>alert(1)</script>"}
+
+	for _, stream := range []bool{false, true} {
+		config := newTestConfig(t, WithPageScriptTag(true), WithRootElementID("root"), WithHTMLStreaming(stream))
+		body := serveTestPage(config, NewPage("Welcome", props), httptest.NewRequest("GET", "/", nil)).Body.String()
+
+		match := pageScriptTag.FindStringSubmatch(body)
+		if match == nil {
+			t.Fatalf("stream=%v: page script tag not found in: %s", stream, body)
+		}
+		if strings.Contains(match[1], "</script>") {
+			t.Errorf("stream=%v: page json must not close the script tag: %s", stream, match[1])
+		}
+
+		var data InertiaPage
+		err := json.Unmarshal([]byte(match[1]), &data)
+		if err != nil {
+			t.Fatalf("stream=%v: decoding page json: %v", stream, err)
+		}
+		if data.Props["evil"] != props["evil"] {
+			t.Errorf("stream=%v: prop mismatch: %v", stream, data.Props["evil"])
+		}
+	}
+}
+
+func TestPage_Status(t *testing.T) {
+	ssrConfig := newTestConfig(t, WithSSRRenderer(&stubSSRRenderer{body: "<div id=\"app\"></div>"}))
+	streamConfig := newTestConfig(t, WithHTMLStreaming(true))
+	htmlConfig := newTestConfig(t)
+
+	tests := []struct {
+		name string
+		r    *httptest.ResponseRecorder
+	}{
+		{"json", serveTestPage(htmlConfig, NewPage("Forbidden", nil).Status(403), inertiaRequest(htmlConfig, "/"))},
+		{"html", serveTestPage(htmlConfig, NewPage("Forbidden", nil).Status(403), httptest.NewRequest("GET", "/", nil))},
+		{"stream", serveTestPage(streamConfig, NewPage("Forbidden", nil).Status(403), httptest.NewRequest("GET", "/", nil))},
+		{"ssr", serveTestPage(ssrConfig, NewPage("Forbidden", nil).Status(403), httptest.NewRequest("GET", "/", nil))},
+	}
+	for _, tt := range tests {
+		if tt.r.Code != 403 {
+			t.Errorf("%s: expected status 403, got %d", tt.name, tt.r.Code)
+		}
+	}
+}
+
+func TestPage_StatusTemplateError(t *testing.T) {
+	for _, opts := range [][]OptFunc{
+		nil,
+		{WithSSRRenderer(&stubSSRRenderer{body: "<div id=\"app\"></div>"})},
+	} {
+		config, err := New(func(t *template.Template) (*template.Template, error) {
+			return t.Parse(`{{ .InertiaRoot }}{{ template "missing" }}`)
+		}, testFrontend, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := httptest.NewRecorder()
+		Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := NewPage("Forbidden", nil).Status(http.StatusForbidden).Render(r.Context(), w)
+			if err == nil {
+				t.Fatal("expected a template error")
+			}
+			// nothing has been written yet, so the failure can still be reported
+			http.Error(w, "render failed", http.StatusInternalServerError)
+		})).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestPage_RenderHistory(t *testing.T) {
+	config := newTestConfig(t)
+
+	rec := httptest.NewRecorder()
+	Middleware(config, WithHistoryEncryption(true))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		EncryptHistory(r.Context(), false)
+		ClearHistory(r.Context())
+		_ = NewPage("Welcome", nil).Render(r.Context(), w)
+	})).ServeHTTP(rec, inertiaRequest(config, "/"))
+
+	var data InertiaPage
+	err := json.Unmarshal(rec.Body.Bytes(), &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.EncryptHistory {
+		t.Error("EncryptHistory should override the middleware option")
+	}
+	if !data.ClearHistory {
+		t.Error("ClearHistory should apply to the rendered page")
+	}
+}
+
+func TestPage_SharedPropCache(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		opts  []OptFunc
+		calls int32
+	}{
+		{"without cache", nil, 2},
+		{"with cache", []OptFunc{WithPropCache(0, 0)}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig(t, tt.opts...)
+
+			var calls atomic.Int32
+			release := make(chan struct{})
+			stats := prop.Memo("stats", func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			}).Shared()
+
+			var wg sync.WaitGroup
+			for range 2 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					serveTestPage(config, NewPage("Stats", Props{"stats": stats}), inertiaRequest(config, "/"))
+				}()
+			}
+			// give the requests time to join a running resolver
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls.Load() != tt.calls {
+				t.Errorf("expected %d calls, got: %d", tt.calls, calls.Load())
+			}
+		})
+	}
+}
+
+func benchmarkProps() Props {
+	rows := make([]map[string]any, 2000)
+	for i := range rows {
+		rows[i] = map[string]any{
+			"id":          i,
+			"name":        fmt.Sprintf("user <%d>", i),
+			"description": "Lorem ipsum dolor sit amet, 'consectetur' adipiscing elit & more",
+		}
+	}
+	return Props{"rows": rows}
+}
+
+func benchmarkRenderHtml(b *testing.B, config *Config) {
+	p := NewPage("Bench", nil)
+	data := &InertiaPage{Component: "Bench", Url: "/bench", Props: benchmarkProps()}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		err := p.renderHtml(context.Background(), config, config.rootLayout, io.Discard, data)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPage_RenderHtml(b *testing.B) {
+	config, err := New(testTemplate, testFrontend)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkRenderHtml(b, config)
+}
+
+func BenchmarkPage_StreamHtml(b *testing.B) {
+	config, err := New(testTemplate, testFrontend, WithHTMLStreaming(true))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkRenderHtml(b, config)
+}
@@ -14,3 +14,30 @@ func SetProp(ctx context.Context, key string, value any) {
 	}
 	bag.Set(key, value)
 }
+
+// setupBag applies the prop settings of the config to a request bag
+func (s *Config) setupBag(bag *prop.Bag) *prop.Bag {
+	return bag.SetLimit(s.propLimit).
+		SetSharedCache(s.propCache).
+		SetTracer(s.hooks).
+		SetLogger(s.logger)
+}
+
+// SetTemplateData sets a value that is available as {{ .Data.key }} in the root template for the current request
+func SetTemplateData(ctx context.Context, key string, value any) {
+	data, ok := ctx.Value(templateDataKey).(map[string]any)
+	if !ok {
+		panic("yaigo.SetTemplateData: could not find template data in ctx")
+	}
+	data[key] = value
+}
+
+// SetPropConcurrency limits how many async props are resolved at the same time for the current request,
+// overriding WithPropConcurrency. n <= 0 means no limit.
+func SetPropConcurrency(ctx context.Context, n int) {
+	bag, ok := ctx.Value(bagKey).(*prop.Bag)
+	if !ok {
+		panic("yaigo.SetPropConcurrency: could not find bag in ctx")
+	}
+	bag.SetLimit(n)
+}
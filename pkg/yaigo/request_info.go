@@ -22,6 +22,7 @@ type RequestInfo struct {
 	PartialComponentHeader string
 	PartialOnlyHeader      string
 	PartialExceptHeader    string
+	AcceptLanguageHeader   string
 }
 
 func (ri *RequestInfo) IsPartial(page string) bool {
@@ -36,6 +37,7 @@ func (ri *RequestInfo) Fill(r *http.Request) {
 	ri.PartialComponentHeader = h.Get(HeaderPartialComponent)
 	ri.PartialOnlyHeader = h.Get(HeaderPartialOnly)
 	ri.PartialExceptHeader = h.Get(HeaderPartialExcept)
+	ri.AcceptLanguageHeader = h.Get("Accept-Language")
 }
 
 func (ri *RequestInfo) Empty() {
@@ -45,6 +47,7 @@ func (ri *RequestInfo) Empty() {
 	ri.PartialComponentHeader = ""
 	ri.PartialOnlyHeader = ""
 	ri.PartialExceptHeader = ""
+	ri.AcceptLanguageHeader = ""
 }
 
 // IsVersionConflict redirects the request if the manifest version is outdated on the client, returns true if it has been redirected
@@ -77,3 +80,14 @@ func (ri *RequestInfo) ExceptProps() []string {
 	}
 	return strings.Split(ri.PartialExceptHeader, ",")
 }
+
+// Locale returns the first language of the Accept-Language header, e.g. "en-US"
+func (ri *RequestInfo) Locale() string {
+	lang, _, _ := strings.Cut(ri.AcceptLanguageHeader, ",")
+	lang, _, _ = strings.Cut(lang, ";")
+	lang = strings.TrimSpace(lang)
+	if lang == "*" {
+		return ""
+	}
+	return lang
+}
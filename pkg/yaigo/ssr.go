@@ -0,0 +1,101 @@
+package yaigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/tortlewortle/yaigo/internal/page"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// InertiaPage is the page object sent to the client and the SSR renderer
+type InertiaPage = page.InertiaPage
+
+// SSRRenderer renders an inertia page server side, returning the head tags and the body html
+//
+// Renderers should wrap ErrSSRUnavailable when the page should be rendered client side instead.
+type SSRRenderer interface {
+	Render(ctx context.Context, page *InertiaPage) (head []string, body string, err error)
+}
+
+// ErrSSRUnavailable makes the page fall back to client side rendering when returned by an SSRRenderer
+var ErrSSRUnavailable = errors.New("could not communicate with ssr server")
+
+type ssrResponse struct {
+	Head []string `json:"head"`
+	Body string   `json:"body"`
+}
+
+// HTTPSSRRenderer renders pages using the inertia ssr server over http
+type HTTPSSRRenderer struct {
+	ssrHTTPClient *http.Client
+	ssrURL        string
+}
+
+// NewHTTPSSRRenderer creates a renderer that talks to the inertia ssr server at ssrURL
+//
+// Use a unix:///path/to/ssr.sock url to talk to the ssr server over a unix domain socket.
+func NewHTTPSSRRenderer(ssrURL string, timeout time.Duration) *HTTPSSRRenderer {
+	ssrTransport := http.DefaultTransport.(*http.Transport).Clone()
+	ssrTransport.MaxIdleConns = 100
+	ssrTransport.MaxConnsPerHost = 100
+	ssrTransport.MaxIdleConnsPerHost = 100
+
+	if socketPath, ok := strings.CutPrefix(ssrURL, unixSocketPrefix); ok {
+		var dialer net.Dialer
+		ssrTransport.Proxy = nil
+		ssrTransport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		// the host is ignored by the dialer but required to build requests
+		ssrURL = "http://ssr"
+	}
+
+	return &HTTPSSRRenderer{
+		ssrHTTPClient: &http.Client{
+			Timeout:   timeout,
+			Transport: ssrTransport,
+		},
+		ssrURL: ssrURL,
+	}
+}
+
+const unixSocketPrefix = "unix://"
+
+func (r *HTTPSSRRenderer) Render(ctx context.Context, data *InertiaPage) ([]string, string, error) {
+	renderPath, err := url.JoinPath(r.ssrURL, "/render")
+	if err != nil {
+		return nil, "", err
+	}
+	pData, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ssrReq, err := http.NewRequestWithContext(ctx, "GET", renderPath, bytes.NewReader(pData))
+	if err != nil {
+		return nil, "", errors.Join(ErrSSRUnavailable, err)
+	}
+
+	resp, err := r.ssrHTTPClient.Do(ssrReq)
+	if err != nil {
+		return nil, "", errors.Join(ErrSSRUnavailable, err)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	var ssrRes ssrResponse
+	err = json.NewDecoder(resp.Body).Decode(&ssrRes)
+	if err != nil {
+		return nil, "", errors.Join(ErrSSRUnavailable, err)
+	}
+
+	return ssrRes.Head, ssrRes.Body, nil
+}
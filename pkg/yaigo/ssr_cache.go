@@ -0,0 +1,87 @@
+package yaigo
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type ssrCacheKey [sha256.Size]byte
+
+type ssrCacheEntry struct {
+	key     ssrCacheKey
+	head    []string
+	body    string
+	expires time.Time
+}
+
+// ssrCache is a lru cache of ssr results keyed by the hash of the marshalled page object
+type ssrCache struct {
+	lock       sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[ssrCacheKey]*list.Element
+	order      *list.List
+}
+
+func newSSRCache(ttl time.Duration, maxEntries int) *ssrCache {
+	return &ssrCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[ssrCacheKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func ssrCacheKeyFor(data *InertiaPage) (ssrCacheKey, error) {
+	pData, err := json.Marshal(data)
+	if err != nil {
+		return ssrCacheKey{}, err
+	}
+	return sha256.Sum256(pData), nil
+}
+
+func (c *ssrCache) get(key ssrCacheKey) ([]string, string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, "", false
+	}
+	entry := el.Value.(*ssrCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, "", false
+	}
+	c.order.MoveToFront(el)
+	return entry.head, entry.body, true
+}
+
+func (c *ssrCache) set(key ssrCacheKey, head []string, body string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := &ssrCacheEntry{
+		key:     key,
+		head:    head,
+		body:    body,
+		expires: time.Now().Add(c.ttl),
+	}
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*ssrCacheEntry).key)
+	}
+}
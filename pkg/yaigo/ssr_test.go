@@ -0,0 +1,183 @@
+package yaigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/tortlewortle/yaigo/internal/testfixture"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testFrontend = testfixture.Frontend
+	testTemplate = testfixture.Template
+)
+
+func newTestConfig(t *testing.T, opts ...OptFunc) *Config {
+	t.Helper()
+	config, err := New(testTemplate, testFrontend, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func serveTestPage(config *Config, p *Page, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = p.Render(r.Context(), w)
+	})).ServeHTTP(rec, r)
+	return rec
+}
+
+type stubSSRRenderer struct {
+	head  []string
+	body  string
+	err   error
+	calls int
+}
+
+func (s *stubSSRRenderer) Render(_ context.Context, _ *InertiaPage) ([]string, string, error) {
+	s.calls++
+	return s.head, s.body, s.err
+}
+
+func TestPage_RenderSSR(t *testing.T) {
+	config := newTestConfig(t, WithSSRRenderer(&stubSSRRenderer{
+		head: []string{"<title>ssr</title>"},
+		body: "<div id=\"app\">rendered</div>",
+	}))
+
+	rec := serveTestPage(config, NewPage("Welcome", nil), httptest.NewRequest("GET", "/", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "<title>ssr</title>") {
+		t.Errorf("ssr head missing: %s", body)
+	}
+	if !strings.Contains(body, "rendered") {
+		t.Errorf("ssr body missing: %s", body)
+	}
+}
+
+func TestPage_RenderSSRFallback(t *testing.T) {
+	config := newTestConfig(t, WithSSRRenderer(&stubSSRRenderer{
+		err: errors.Join(ErrSSRUnavailable, errors.New("connection refused")),
+	}))
+
+	rec := serveTestPage(config, NewPage("Welcome", nil), httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(rec.Body.String(), "data-page=") {
+		t.Errorf("expected client side render fallback, got: %s", rec.Body.String())
+	}
+}
+
+func TestPage_RenderSSRError(t *testing.T) {
+	config := newTestConfig(t, WithSSRRenderer(&stubSSRRenderer{
+		err: errors.New("render failed"),
+	}))
+
+	p := NewPage("Welcome", nil)
+	var renderErr error
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		renderErr = p.Render(r.Context(), w)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if renderErr == nil {
+		t.Error("expected render error to be returned")
+	}
+}
+
+func TestHTTPSSRRenderer_UnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "ssr.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/render" {
+			http.NotFound(w, r)
+			return
+		}
+		var data InertiaPage
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ssrResponse{
+			Head: []string{"<title>" + data.Component + "</title>"},
+			Body: "<div id=\"app\"></div>",
+		})
+	}))
+	_ = srv.Listener.Close()
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	renderer := NewHTTPSSRRenderer("unix://"+socketPath, time.Second)
+	head, body, err := renderer.Render(context.Background(), &InertiaPage{Component: "Welcome"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head) != 1 || head[0] != "<title>Welcome</title>" {
+		t.Errorf("unexpected head: %v", head)
+	}
+	if body != "<div id=\"app\"></div>" {
+		t.Errorf("unexpected body: %s", body)
+	}
+}
+
+func TestHTTPSSRRenderer_UnixSocketUnavailable(t *testing.T) {
+	renderer := NewHTTPSSRRenderer("unix://"+filepath.Join(t.TempDir(), "missing.sock"), time.Second)
+	_, _, err := renderer.Render(context.Background(), &InertiaPage{Component: "Welcome"})
+	if !errors.Is(err, ErrSSRUnavailable) {
+		t.Errorf("expected ErrSSRUnavailable, got: %v", err)
+	}
+}
+
+func TestPage_RenderSSRCache(t *testing.T) {
+	renderer := &stubSSRRenderer{body: "<div id=\"app\">rendered</div>"}
+	config := newTestConfig(t, WithSSRRenderer(renderer), WithSSRCache(time.Minute, 10))
+
+	for range 3 {
+		serveTestPage(config, NewPage("Welcome", Props{"title": "hello"}), httptest.NewRequest("GET", "/", nil))
+	}
+	if renderer.calls != 1 {
+		t.Errorf("expected 1 ssr call for identical pages, got %d", renderer.calls)
+	}
+
+	serveTestPage(config, NewPage("Welcome", Props{"title": "other"}), httptest.NewRequest("GET", "/", nil))
+	if renderer.calls != 2 {
+		t.Errorf("expected a new ssr call for different props, got %d", renderer.calls)
+	}
+
+	for range 2 {
+		serveTestPage(config, NewPage("Welcome", Props{"title": "hello"}).NoSSRCache(), httptest.NewRequest("GET", "/", nil))
+	}
+	if renderer.calls != 4 {
+		t.Errorf("expected NoSSRCache to bypass the cache, got %d calls", renderer.calls)
+	}
+}
+
+func TestSSRCache_Eviction(t *testing.T) {
+	cache := newSSRCache(time.Minute, 2)
+	keys := []ssrCacheKey{{1}, {2}, {3}}
+	for _, k := range keys {
+		cache.set(k, nil, "body")
+	}
+	if _, _, ok := cache.get(keys[0]); ok {
+		t.Error("oldest entry should be evicted")
+	}
+	if _, _, ok := cache.get(keys[2]); !ok {
+		t.Error("newest entry should be cached")
+	}
+
+	expiring := newSSRCache(-time.Second, 2)
+	expiring.set(keys[0], nil, "body")
+	if _, _, ok := expiring.get(keys[0]); ok {
+		t.Error("expired entry should not be returned")
+	}
+}
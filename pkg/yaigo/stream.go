@@ -0,0 +1,244 @@
+package yaigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/tortlewortle/yaigo/internal/page"
+	"golang.org/x/net/html"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// inertiaRootPlaceholder marks where the page object gets streamed into the executed root template
+const inertiaRootPlaceholder = "<!--yaigo:inertia-root-->"
+
+var errNoInertiaRoot = errors.New("root template does not render {{ .InertiaRoot }}")
+
+var templateBufPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// attrEscapeWriter html escapes everything written to it so it can be used inside an attribute,
+// escaping the same set of characters as html.EscapeString
+type attrEscapeWriter struct {
+	w io.Writer
+}
+
+var (
+	escAmp  = []byte("&amp;")
+	escApos = []byte("&#39;")
+	escLt   = []byte("&lt;")
+	escGt   = []byte("&gt;")
+	escQuot = []byte("&#34;")
+	escCr   = []byte("&#13;")
+)
+
+func (a attrEscapeWriter) Write(p []byte) (int, error) {
+	var last int
+	for i, c := range p {
+		var esc []byte
+		switch c {
+		case '&':
+			esc = escAmp
+		case '\'':
+			esc = escApos
+		case '<':
+			esc = escLt
+		case '>':
+			esc = escGt
+		case '"':
+			esc = escQuot
+		case '\r':
+			esc = escCr
+		default:
+			continue
+		}
+		_, err := a.w.Write(p[last:i])
+		if err != nil {
+			return 0, err
+		}
+		_, err = a.w.Write(esc)
+		if err != nil {
+			return 0, err
+		}
+		last = i + 1
+	}
+	_, err := a.w.Write(p[last:])
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// lazyStartWriter calls start before the first byte is written, so the headers are only committed then
+type lazyStartWriter struct {
+	w       io.Writer
+	start   func()
+	started bool
+}
+
+func (l *lazyStartWriter) begin() {
+	if !l.started {
+		l.started = true
+		l.start()
+	}
+}
+
+func (l *lazyStartWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	l.begin()
+	return l.w.Write(p)
+}
+
+// patternWriter passes everything through to w, calling onMatch in place of every occurrence of pattern.
+// Close has to be called to write the bytes held back at the end.
+type patternWriter struct {
+	w       io.Writer
+	pattern []byte
+	pending []byte
+	matches int
+	onMatch func(w io.Writer) error
+}
+
+func (s *patternWriter) Write(p []byte) (int, error) {
+	data := p
+	if len(s.pending) > 0 {
+		data = append(s.pending, p...)
+		s.pending = nil
+	}
+
+	for {
+		before, after, found := bytes.Cut(data, s.pattern)
+		if !found {
+			break
+		}
+		_, err := s.w.Write(before)
+		if err != nil {
+			return 0, err
+		}
+		s.matches++
+		err = s.onMatch(s.w)
+		if err != nil {
+			return 0, err
+		}
+		data = after
+	}
+
+	// hold back the start of a pattern that may be completed by the next write
+	keep := partialPrefixLen(data, s.pattern)
+	s.pending = append(s.pending, data[len(data)-keep:]...)
+	_, err := s.w.Write(data[:len(data)-keep])
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *patternWriter) Close() error {
+	_, err := s.w.Write(s.pending)
+	s.pending = nil
+	return err
+}
+
+// partialPrefixLen returns the length of the longest suffix of data that is a prefix of pattern
+func partialPrefixLen(data []byte, pattern []byte) int {
+	for n := min(len(data), len(pattern)-1); n > 0; n-- {
+		if bytes.Equal(data[len(data)-n:], pattern[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// streamHtml executes the root template straight into the response, flushing everything up until the inertia root
+// so the browser can start fetching assets, and encodes the page object in its place.
+//
+// The headers are only committed on the first write so a template failing before that can still be answered with
+// an error, errors after it can not change the status code anymore since the response has already started.
+func (p *Page) streamHtml(ctx context.Context, config *Config, root *layout, w io.Writer, data *page.InertiaPage) error {
+	rootID := html.EscapeString(config.rootElementID)
+	tmplData := p.rootTemplateData(ctx, config, data, inertiaRootPlaceholder, nil)
+
+	lw := &lazyStartWriter{
+		w: w,
+		start: func() {
+			if rw, ok := w.(http.ResponseWriter); ok {
+				rw.Header().Set("Content-Type", "text/html")
+				p.writeStatus(ctx, rw)
+			}
+		},
+	}
+	rootW := &patternWriter{
+		w:       lw,
+		pattern: []byte(inertiaRootPlaceholder),
+		onMatch: func(out io.Writer) error {
+			// let the browser start fetching the assets in the head
+			lw.begin()
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			return writeInertiaRoot(out, rootID, config.pageScriptTag, data)
+		},
+	}
+	nonceW := &patternWriter{
+		w:       rootW,
+		pattern: []byte(nonceAttrPlaceholder),
+		onMatch: func(out io.Writer) error {
+			_, err := io.WriteString(out, nonceAttr(tmplData.Nonce))
+			return err
+		},
+	}
+
+	err := root.template.Execute(nonceW, tmplData)
+	if err != nil {
+		return err
+	}
+	err = nonceW.Close()
+	if err != nil {
+		return err
+	}
+	err = rootW.Close()
+	if err != nil {
+		return err
+	}
+	if rootW.matches == 0 {
+		return errNoInertiaRoot
+	}
+	return nil
+}
+
+// writeInertiaRoot encodes the page object into w, the encoder marshals the page in one go but it is written
+// without converting it to a string
+func writeInertiaRoot(w io.Writer, rootID string, scriptTag bool, data *page.InertiaPage) error {
+	if scriptTag {
+		// the encoder escapes <, > and & so the json can never close the script tag
+		_, err := io.WriteString(w, `<script data-page="`+rootID+`" type="application/json">`)
+		if err != nil {
+			return err
+		}
+		err = json.NewEncoder(w).Encode(data)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, `</script><div id="`+rootID+`"></div>`)
+		return err
+	}
+
+	_, err := io.WriteString(w, `<div id="`+rootID+`" data-page='`)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(attrEscapeWriter{w}).Encode(data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `'></div>`)
+	return err
+}
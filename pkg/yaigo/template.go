@@ -3,6 +3,7 @@ package yaigo
 import (
 	"fmt"
 	"github.com/tortlewortle/yaigo/pkg/vite"
+	"golang.org/x/net/html"
 	"html/template"
 	"net/url"
 	"strings"
@@ -11,6 +12,13 @@ import (
 type rootTmplData struct {
 	InertiaRoot template.HTML
 	InertiaHead template.HTML
+	Nonce       string
+	// Page is the inertia page object, e.g. {{ .Page.Component }}
+	Page *InertiaPage
+	// Locale is the preferred language of the Accept-Language header, e.g. <html lang="{{ or .Locale "en" }}">
+	Locale string
+	// Data is set per request using SetTemplateData
+	Data map[string]any
 }
 
 func generateRootTemplate(tfn func(*template.Template) (*template.Template, error), manifest *vite.Manifest, opts *ServerOpts) (*template.Template, error) {
@@ -20,6 +28,30 @@ func generateRootTemplate(tfn func(*template.Template) (*template.Template, erro
 	}
 	t := template.New("rootTemplate")
 
+	// integrityAttrs returns the integrity and crossorigin attributes when SRI is enabled
+	integrityAttrs := func(file string) string {
+		if !opts.SRI {
+			return ""
+		}
+		hash, ok := manifest.Integrity(file)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf(" integrity=\"%s\" crossorigin=\"anonymous\"", hash)
+	}
+
+	// nonceAttrs returns the nonce attribute for the emitted tags, without an explicit nonce the request nonce is
+	// filled in while rendering
+	nonceAttrs := func(nonce []string) string {
+		if len(nonce) > 0 {
+			return nonceAttr(nonce[0])
+		}
+		if opts.NonceFunc != nil {
+			return nonceAttrPlaceholder
+		}
+		return ""
+	}
+
 	t = t.Funcs(template.FuncMap{
 		"vite": func(assetUrl string) (string, error) {
 			item, err := manifest.GetItem(assetUrl)
@@ -30,9 +62,10 @@ func generateRootTemplate(tfn func(*template.Template) (*template.Template, erro
 				return viteUrl.JoinPath(assetUrl).String(), nil
 			}
 
-			return "/" + item.File, nil
+			return assetURL(opts.AssetBaseURL, item.File), nil
 		},
-		"viteCSS": func(scriptUrl string) (template.HTML, error) {
+		// the tags get the request nonce, passing one overrides it, e.g. {{ viteCSS "src/main.ts" }}
+		"viteCSS": func(scriptUrl string, nonce ...string) (template.HTML, error) {
 			// dev Config provides the css by itself
 			if opts.ViteUrl != "" {
 				return "", nil
@@ -42,16 +75,37 @@ func generateRootTemplate(tfn func(*template.Template) (*template.Template, erro
 			if err != nil {
 				return "", err
 			}
+			attrs := nonceAttrs(nonce)
 			for _, sheetUrl := range item.Css {
-				tb.WriteString(fmt.Sprintf("<link rel=\"preload\" href=\"/%s\" as=\"style\"/>\n", sheetUrl))
+				tb.WriteString(fmt.Sprintf("<link rel=\"preload\" href=\"%s\" as=\"style\"%s%s/>\n", html.EscapeString(assetURL(opts.AssetBaseURL, sheetUrl)), integrityAttrs(sheetUrl), attrs))
 			}
 			tb.WriteString("\n")
 			for _, sheetUrl := range item.Css {
-				tb.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"/%s\"/>\n", sheetUrl))
+				tb.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"%s\"%s%s/>\n", html.EscapeString(assetURL(opts.AssetBaseURL, sheetUrl)), integrityAttrs(sheetUrl), attrs))
 			}
 			return template.HTML(tb.String()), nil
 		},
+		// viteScript emits the full module script tag for the entry, including the integrity and request nonce attributes
+		// e.g. {{ viteScript "src/main.ts" }}
+		"viteScript": func(scriptUrl string, nonce ...string) (template.HTML, error) {
+			item, err := manifest.GetItem(scriptUrl)
+			if err != nil {
+				return "", err
+			}
+			attrs := nonceAttrs(nonce)
+			if opts.ViteUrl != "" {
+				src := html.EscapeString(viteUrl.JoinPath(scriptUrl).String())
+				return template.HTML(fmt.Sprintf("<script type=\"module\" src=\"%s\"%s></script>", src, attrs)), nil
+			}
+			src := html.EscapeString(assetURL(opts.AssetBaseURL, item.File))
+			return template.HTML(fmt.Sprintf("<script type=\"module\" src=\"%s\"%s%s></script>", src, integrityAttrs(item.File), attrs)), nil
+		},
 	})
 
 	return tfn(t)
 }
+
+// assetURL returns the public url of a built asset, relative to the site root when base is empty
+func assetURL(base string, file string) string {
+	return strings.TrimSuffix(base, "/") + "/" + file
+}
@@ -0,0 +1,73 @@
+package yaigo
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTemplate_SubresourceIntegrity(t *testing.T) {
+	tmpl := func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`<html><head>{{ viteCSS "src/main.ts" }}{{ viteScript "src/main.ts" .Nonce }}</head><body>{{ .InertiaRoot }}</body></html>`)
+	}
+	config, err := New(tmpl, testFrontend, WithSubresourceIntegrity(true), WithCSPNonceFunc(GenerateNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := serveTestPage(config, NewPage("Welcome", nil), httptest.NewRequest("GET", "/", nil)).Body.String()
+	if !strings.Contains(body, `<script type="module" src="/assets/main-abc123.js" integrity="sha384-`) {
+		t.Errorf("script integrity missing: %s", body)
+	}
+	if strings.Count(body, `crossorigin="anonymous"`) != 3 {
+		t.Errorf("expected crossorigin on the script, preload and stylesheet: %s", body)
+	}
+	if !strings.Contains(body, `<link rel="stylesheet" href="/assets/main-def456.css" integrity="sha384-`) {
+		t.Errorf("stylesheet integrity missing: %s", body)
+	}
+}
+
+func TestTemplate_AssetBaseURL(t *testing.T) {
+	tmpl := func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`<html><head>{{ viteCSS "src/main.ts" }}<script src="{{ vite "src/main.ts" }}"></script>{{ viteScript "src/main.ts" }}</head><body>{{ .InertiaRoot }}</body></html>`)
+	}
+	config, err := New(tmpl, testFrontend, WithAssetBaseURL("https://cdn.example.com/app/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := serveTestPage(config, NewPage("Welcome", nil), httptest.NewRequest("GET", "/", nil)).Body.String()
+	if strings.Count(body, `src="https://cdn.example.com/app/assets/main-abc123.js"`) != 2 {
+		t.Errorf("script urls should use the asset base url: %s", body)
+	}
+	if strings.Count(body, `href="https://cdn.example.com/app/assets/main-def456.css"`) != 2 {
+		t.Errorf("stylesheet urls should use the asset base url: %s", body)
+	}
+}
+
+func TestTemplate_RootData(t *testing.T) {
+	tmpl := func(t *template.Template) (*template.Template, error) {
+		return t.Parse(`<html lang="{{ or .Locale "en" }}" class="{{ .Data.theme }}" data-component="{{ .Page.Component }}"><body>{{ .InertiaRoot }}</body></html>`)
+	}
+	config, err := New(tmpl, testFrontend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stream := range []bool{false, true} {
+		config.streamHTML = stream
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Language", "nl-NL,nl;q=0.9,en;q=0.8")
+		rec := httptest.NewRecorder()
+		Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SetTemplateData(r.Context(), "theme", "dark")
+			_ = NewPage("Users/Index", nil).Render(r.Context(), w)
+		})).ServeHTTP(rec, r)
+
+		if !strings.HasPrefix(rec.Body.String(), `<html lang="nl-NL" class="dark" data-component="Users/Index">`) {
+			t.Errorf("stream=%v: unexpected root template output: %s", stream, rec.Body.String())
+		}
+	}
+}
@@ -2,10 +2,12 @@ package yaigo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/tortlewortle/yaigo/internal/page"
 	"github.com/tortlewortle/yaigo/pkg/prop"
+	"github.com/tortlewortle/yaigo/pkg/route"
 	"github.com/tortlewortle/yaigo/pkg/typegen"
 	"os"
 	"path/filepath"
@@ -45,7 +47,9 @@ func GeneratePropTypes(ctx context.Context, typename string) error {
 		if err != nil {
 			return fmt.Errorf("GetProps failed: %v", err)
 		}
+		_, end := config.hooks.StartTypeGen(ctx, typename)
 		err = config.typeGenerator.GenerateFromProps(typename, props)
+		end(err)
 		if err != nil {
 			return fmt.Errorf("GenerateFromProps failed: %v", err)
 		}
@@ -181,3 +185,72 @@ func generateTypeScriptForComponent(typeDir string, root typegen.TsType) error {
 
 	return nil
 }
+
+// GenerateRoutes writes routes.gen.ts to the output directory with the route table, the param types per route and
+// a route() helper building urls the same way as route.URL
+func (g *TypeGenerator) GenerateRoutes(routes []route.Route) error {
+	var builder strings.Builder
+	builder.WriteString("// Code generated by yaigo. DO NOT EDIT.\n")
+	builder.WriteString("// versions:\n")
+	builder.WriteString("//   yaigo: v0.1.0\n")
+	builder.WriteString("\n")
+
+	builder.WriteString("export const routes = {\n")
+	for _, r := range routes {
+		builder.WriteString(fmt.Sprintf("    %s: { method: %s, path: %s },\n", jsString(r.Name), jsString(r.Method), jsString(r.Path)))
+	}
+	builder.WriteString("} as const\n\n")
+
+	builder.WriteString("export type RouteName = keyof typeof routes\n\n")
+
+	builder.WriteString("export type RouteParams = {\n")
+	for _, r := range routes {
+		var params []string
+		for _, p := range r.Params() {
+			params = append(params, fmt.Sprintf("%s: string | number", jsString(p)))
+		}
+		if len(params) == 0 {
+			builder.WriteString(fmt.Sprintf("    %s: {};\n", jsString(r.Name)))
+		} else {
+			builder.WriteString(fmt.Sprintf("    %s: { %s };\n", jsString(r.Name), strings.Join(params, ", ")))
+		}
+	}
+	builder.WriteString("}\n\n")
+
+	// params can only be left out when the route has no path params
+	builder.WriteString(`export type RouteArgs<N extends RouteName> = {} extends RouteParams[N]
+    ? [params?: RouteParams[N] & Record<string, string | number>]
+    : [params: RouteParams[N] & Record<string, string | number>]
+
+export function route<N extends RouteName>(name: N, ...[params]: RouteArgs<N>): string {
+    const values: Record<string, string | number> = { ...params }
+    const path = routes[name].path.replace(/\{([^}]*)}/g, (_, wildcard: string) => {
+        if (wildcard === "$") {
+            return ""
+        }
+        const rest = wildcard.endsWith("...")
+        const key = rest ? wildcard.slice(0, -3) : wildcard
+        if (!(key in values)) {
+            throw new Error(` + "`route ${name}: missing param ${key}`" + `)
+        }
+        const value = String(values[key])
+        delete values[key]
+        return rest ? value.split("/").map(encodeURIComponent).join("/") : encodeURIComponent(value)
+    })
+    const query = new URLSearchParams(Object.entries(values).map(([k, v]) => [k, String(v)])).toString()
+    return query ? ` + "`${path}?${query}`" + ` : path
+}
+`)
+
+	err := os.WriteFile(filepath.Join(g.dirPath, "routes.gen.ts"), []byte(builder.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("writing routes: %w", err)
+	}
+	return nil
+}
+
+// jsString quotes s as a javascript string literal
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,47 @@
+package yaigo
+
+import (
+	"github.com/tortlewortle/yaigo/pkg/route"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTypeGenerator_GenerateRoutes(t *testing.T) {
+	rt := route.NewRouter()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	rt.HandleFunc("home", "GET /{$}", noop)
+	rt.HandleFunc("users.show", "GET /users/{id}/{tab...}", noop)
+
+	dir := t.TempDir()
+	err := NewTypeGenerator(dir).GenerateRoutes(rt.Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.ReadFile(filepath.Join(dir, "routes.gen.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(filepath.Join(dir, "routes.gen.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm()&0133 != 0 {
+		t.Errorf("routes.gen.ts should not be executable or writable by others: %s", stat.Mode())
+	}
+	for _, expected := range []string{
+		`"home": { method: "GET", path: "/{$}" },`,
+		`"users.show": { method: "GET", path: "/users/{id}/{tab...}" },`,
+		`"home": {};`,
+		`"users.show": { "id": string | number, "tab": string | number };`,
+		`export type RouteArgs<N extends RouteName> = {} extends RouteParams[N]`,
+		`export function route<N extends RouteName>(name: N, ...[params]: RouteArgs<N>): string`,
+	} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("expected %s in:\n%s", expected, out)
+		}
+	}
+}